@@ -1,44 +1,87 @@
 package config
 
 import (
+	"bytes"
 	"crypto/sha256"
+	_ "embed"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
+// Errors wrapped by configuration loading so callers can tell failures
+// apart with errors.Is
+var (
+	// ErrConfigDownload means the gitleaks configuration couldn't be
+	// downloaded or checked for updates
+	ErrConfigDownload = errors.New("failed to download gitleaks configuration")
+	// ErrConfigDecode means a configuration file isn't valid TOML
+	ErrConfigDecode = errors.New("failed to decode TOML")
+)
+
 // UpdateInfo stores the last update check information
 type UpdateInfo struct {
 	LastCheck time.Time `json:"last_check"`
 	LastHash  string    `json:"last_hash"`
 }
 
+// Allowlist represents a set of conditions that suppress matching findings
+type Allowlist struct {
+	Description    string   `toml:"description,omitempty"`
+	RegexTarget    string   `toml:"regexTarget,omitempty"`
+	Regexes        []string `toml:"regexes,omitempty"`
+	RegexCondition string   `toml:"regexCondition,omitempty"`
+	MinMatches     int      `toml:"minMatches,omitempty"`
+	Stopwords      []string `toml:"stopwords,omitempty"`
+	Condition      string   `toml:"condition,omitempty"`
+	Commits        []string `toml:"commits,omitempty"`
+	Paths          []string `toml:"paths,omitempty"`
+	TargetRules    []string `toml:"targetRules,omitempty"`
+}
+
+// RequiredRegexMatches returns how many of the allowlist's regexes must
+// match: minMatches if set, all of them if regexCondition is AND, and
+// otherwise any one
+func (a Allowlist) RequiredRegexMatches() int {
+	if a.MinMatches > 0 {
+		return a.MinMatches
+	}
+	if a.RegexCondition == "AND" {
+		return len(a.Regexes)
+	}
+	return 1
+}
+
 // Rule represents a single detection rule
 type Rule struct {
-	ID          string   `toml:"id"`
-	Description string   `toml:"description"`
-	Regex       string   `toml:"regex"`
-	SecretGroup int      `toml:"secretGroup"`
-	Entropy     float64  `toml:"entropy"`
-	Path        string   `toml:"path"`
-	Keywords    []string `toml:"keywords"`
-	Tags        []string `toml:"tags"`
-	Allowlists  []struct {
-		Description string   `toml:"description"`
-		RegexTarget string   `toml:"regexTarget"`
-		Regexes     []string `toml:"regexes"`
-		Stopwords   []string `toml:"stopwords"`
-		Condition   string   `toml:"condition"`
-		Commits     []string `toml:"commits"`
-		Paths       []string `toml:"paths"`
-	} `toml:"allowlists"`
+	ID              string      `toml:"id"`
+	Description     string      `toml:"description"`
+	Regex           string      `toml:"regex"`
+	SecretGroup     int         `toml:"secretGroup"`
+	Entropy         float64     `toml:"entropy"`
+	MaxEntropy      float64     `toml:"maxEntropy"`
+	EntropyMode     string      `toml:"entropyMode"`
+	MinSecretLength int         `toml:"minSecretLength"`
+	Path            string      `toml:"path"`
+	Keywords        []string    `toml:"keywords"`
+	Tags            []string    `toml:"tags"`
+	Allowlists      []Allowlist `toml:"allowlists"`
+	// Severity is low, medium, high, or critical. Without it, findings
+	// take the severity a rule's tags name, if any.
+	Severity string `toml:"severity"`
 }
 
 // Config represents the entire configuration
@@ -49,29 +92,110 @@ type Config struct {
 		Path          string   `toml:"path"`
 		DisabledRules []string `toml:"disabledRules"`
 	} `toml:"extend"`
-	Rules      []Rule `toml:"rules"`
-	Allowlists []struct {
-		Description string   `toml:"description"`
-		RegexTarget string   `toml:"regexTarget"`
-		Regexes     []string `toml:"regexes"`
-		Stopwords   []string `toml:"stopwords"`
-		Commits     []string `toml:"commits"`
-		Paths       []string `toml:"paths"`
-		TargetRules []string `toml:"targetRules"`
-	} `toml:"allowlists"`
+	Rules      []Rule      `toml:"rules"`
+	Allowlists []Allowlist `toml:"allowlists"`
+	// IgnoredFingerprints are the fingerprints of individual findings to
+	// drop, e.g. known-safe leaks that have been accepted
+	IgnoredFingerprints []string `toml:"ignoredFingerprints"`
+	// Hash is the hex SHA-256 of the file the configuration was loaded
+	// from, the same hash UpdateInfo.LastHash records for downloads
+	Hash string `toml:"-"`
+	// useDefaultSet records whether the file set [extend] useDefault, for
+	// DropsDefault
+	useDefaultSet bool
+}
+
+// fingerprintRegex matches a finding fingerprint, a hex SHA-256 digest
+var fingerprintRegex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// Validate checks the rules for mistakes that would otherwise silently
+// prevent findings, such as invalid regexes or a secretGroup beyond the
+// regex's capture groups, and the allowlists for impossible conditions
+func (c *Config) Validate() []error {
+	var errs []error
+	for i, allowlist := range c.Allowlists {
+		errs = append(errs, validateAllowlist(fmt.Sprintf("allowlist %d", i+1), allowlist)...)
+	}
+	for _, fingerprint := range c.IgnoredFingerprints {
+		if !fingerprintRegex.MatchString(fingerprint) {
+			errs = append(errs, fmt.Errorf("ignored fingerprint %q is not a SHA-256 hex digest and will never match", fingerprint))
+		}
+	}
+	for _, rule := range c.Rules {
+		for i, allowlist := range rule.Allowlists {
+			errs = append(errs, validateAllowlist(fmt.Sprintf("rule %s allowlist %d", rule.ID, i+1), allowlist)...)
+		}
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s has an invalid regex: %v", rule.ID, err))
+			continue
+		}
+		switch strings.ToLower(rule.Severity) {
+		case "", "low", "medium", "high", "critical":
+		default:
+			errs = append(errs, fmt.Errorf("rule %s has unknown severity %q (expected low, medium, high, or critical)", rule.ID, rule.Severity))
+		}
+		if rule.EntropyMode != "" && rule.EntropyMode != "rune" && rule.EntropyMode != "byte" {
+			errs = append(errs, fmt.Errorf("rule %s has unknown entropyMode %q (expected rune or byte)", rule.ID, rule.EntropyMode))
+		}
+		if rule.SecretGroup > re.NumSubexp() {
+			errs = append(errs, fmt.Errorf("rule %s has secretGroup %d but its regex only has %d capture groups", rule.ID, rule.SecretGroup, re.NumSubexp()))
+		}
+	}
+	return errs
 }
 
-// getRemoteFileHash gets the SHA-256 hash of the remote file
-func getRemoteFileHash(url string) (string, error) {
-	resp, err := http.Get(url)
+// validateAllowlist checks an allowlist's regex condition, naming it by name
+func validateAllowlist(name string, allowlist Allowlist) []error {
+	var errs []error
+	if allowlist.RegexCondition != "" && allowlist.RegexCondition != "OR" && allowlist.RegexCondition != "AND" {
+		errs = append(errs, fmt.Errorf("%s has unknown regexCondition %q (expected OR or AND)", name, allowlist.RegexCondition))
+	}
+	if allowlist.MinMatches > len(allowlist.Regexes) {
+		errs = append(errs, fmt.Errorf("%s has minMatches %d but only %d regexes", name, allowlist.MinMatches, len(allowlist.Regexes)))
+	}
+	return errs
+}
+
+// DefaultRemediation is the fallback guidance for rules without an entry
+const DefaultRemediation = "Rotate or revoke this credential with its provider, remove it from client-side code, and load it from a server-side secret store instead."
+
+//go:embed remediation.json
+var bundledRemediation []byte
+
+// LoadRemediation loads remediation guidance keyed by rule ID. The bundled
+// guidance is always loaded; entries from path (if set) override it.
+func LoadRemediation(path string) (map[string]string, error) {
+	remediation := make(map[string]string)
+	if err := json.Unmarshal(bundledRemediation, &remediation); err != nil {
+		return nil, fmt.Errorf("failed to parse bundled remediation: %v", err)
+	}
+
+	if path == "" {
+		return remediation, nil
+	}
+
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch remote file: %v", err)
+		return nil, fmt.Errorf("failed to read remediation file: %v", err)
 	}
-	defer resp.Body.Close()
 
-	content, err := io.ReadAll(resp.Body)
+	var custom map[string]string
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("failed to parse remediation file: %v", err)
+	}
+	for ruleID, text := range custom {
+		remediation[ruleID] = text
+	}
+
+	return remediation, nil
+}
+
+// getRemoteConfigHash gets the SHA-256 hash of the remote configuration
+func getRemoteConfigHash() (string, error) {
+	content, err := fetchConfig()
 	if err != nil {
-		return "", fmt.Errorf("failed to read remote file: %v", err)
+		return "", err
 	}
 
 	hash := sha256.Sum256(content)
@@ -89,17 +213,79 @@ func getLocalFileHash(filePath string) (string, error) {
 	return hex.EncodeToString(hash[:]), nil
 }
 
-// getConfigDir returns the platform-specific configuration directory
+// Dir overrides the directory holding the configuration, its update info,
+// run state and the Playwright cache. By default they live in ~/.jsweb and
+// the platform cache directory.
+var Dir string
+
+// fallbackWarning is printed once when the home directory is unavailable
+var fallbackWarning sync.Once
+
+// fallbackDir returns $<env>/jsweb, or jsweb in the temp directory when the
+// variable isn't set, for use when the home directory can't be determined
+func fallbackDir(env string, homeErr error) string {
+	dir := os.Getenv(env)
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	fallbackWarning.Do(func() {
+		fmt.Fprintf(os.Stderr, "Warning: home directory unavailable (%v), using %s; set --config-dir to choose another location\n", homeErr, filepath.Join(dir, "jsweb"))
+	})
+	return filepath.Join(dir, "jsweb")
+}
+
+// getConfigDir returns the configuration directory: Dir when set, otherwise
+// ~/.jsweb, falling back to $XDG_CONFIG_HOME or the temp directory
 func getConfigDir() (string, error) {
+	if Dir != "" {
+		return Dir, nil
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", err
+		return fallbackDir("XDG_CONFIG_HOME", err), nil
 	}
 
 	// Use .jsweb in home directory for all platforms
 	return filepath.Join(homeDir, ".jsweb"), nil
 }
 
+// CacheDir returns the directory the Playwright driver and browsers are
+// cached under: Dir when set, otherwise the platform cache directory,
+// falling back to $XDG_CACHE_HOME or the temp directory
+func CacheDir() string {
+	if Dir != "" {
+		return Dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fallbackDir("XDG_CACHE_HOME", err)
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(homeDir, "AppData", "Local")
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Caches")
+	default: // linux and others
+		return filepath.Join(homeDir, ".cache")
+	}
+}
+
+// GetStateDir returns the directory used to persist per-target run state,
+// creating it if needed
+func GetStateDir() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %v", err)
+	}
+
+	stateDir := filepath.Join(configDir, "state")
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %v", err)
+	}
+	return stateDir, nil
+}
+
 // getUpdateInfoPath returns the path to the update info file
 func getUpdateInfoPath(configDir string) string {
 	return filepath.Join(configDir, "update_info.json")
@@ -133,7 +319,7 @@ func saveUpdateInfo(configDir string, info *UpdateInfo) error {
 	}
 
 	infoPath := getUpdateInfoPath(configDir)
-	if err := os.WriteFile(infoPath, data, 0644); err != nil {
+	if err := writeFileAtomic(infoPath, data); err != nil {
 		return fmt.Errorf("failed to write update info: %v", err)
 	}
 
@@ -155,90 +341,327 @@ func shouldCheckForUpdates(info *UpdateInfo, forceUpdate bool) bool {
 	return time.Since(info.LastCheck) >= 24*time.Hour
 }
 
+// configURL is the location of the official Gitleaks configuration
+const configURL = "https://raw.githubusercontent.com/gitleaks/gitleaks/master/config/gitleaks.toml"
+
+// Mirrors are URLs of copies of the Gitleaks configuration, tried in order
+// when it can't be downloaded from configURL. SetMirrors checks them.
+var Mirrors []string
+
+// SetMirrors sets Mirrors after checking that each is an http or https URL
+func SetMirrors(mirrors []string) error {
+	for _, mirror := range mirrors {
+		if u, err := url.Parse(mirror); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("mirror must be an http or https URL: %s", mirror)
+		}
+	}
+	Mirrors = mirrors
+	return nil
+}
+
+// Each configuration URL is tried up to downloadAttempts times in all,
+// waiting downloadBackoff after the first failure and twice as long after
+// each later one
+const (
+	downloadAttempts = 3
+	downloadBackoff  = time.Second
+)
+
+// downloadClient fetches the configuration, with a timeout so a stalled
+// connection moves on to the next attempt
+var downloadClient = &http.Client{Timeout: 30 * time.Second}
+
+// bundledConfig is a copy of the Gitleaks configuration used when none has
+// been downloaded yet and every download fails. It's MIT licensed; see
+// gitleaks.LICENSE.
+//
+//go:embed gitleaks.toml
+var bundledConfig []byte
+
+// Update is a configuration update check running in the background
+type Update struct {
+	done chan struct{}
+	err  error
+}
+
+// Wait blocks until the update check finishes and returns its error
+func (u *Update) Wait() error {
+	if u == nil {
+		return nil
+	}
+	<-u.done
+	return u.err
+}
+
 // LoadConfig loads the configuration from file or downloads it if not present
 func LoadConfig(forceUpdate bool) (*Config, error) {
-	// Get configuration directory
-	configDir, err := getConfigDir()
+	configDir, configPath, fileExists, err := prepareConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config directory: %v", err)
+		return nil, err
 	}
 
-	// Create config directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %v", err)
+	// Load update info
+	updateInfo, err := loadUpdateInfo(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load update info: %v", err)
 	}
 
-	configPath := filepath.Join(configDir, "gitleaks.toml")
-	url := "https://raw.githubusercontent.com/gitleaks/gitleaks/master/config/gitleaks.toml"
+	// If file exists and we should check for updates
+	if fileExists && shouldCheckForUpdates(updateInfo, forceUpdate) {
+		if err := checkForUpdates(configDir, configPath, updateInfo, forceUpdate, os.Stdout); err != nil {
+			return nil, err
+		}
+	} else if !fileExists {
+		// Download if file doesn't exist, falling back to the bundled copy
+		if err := downloadGitleaksConfig(configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using the bundled gitleaks configuration\n", err)
+			if err := writeFileAtomic(configPath, bundledConfig); err != nil {
+				return nil, fmt.Errorf("failed to write bundled config: %v", err)
+			}
+		}
+	}
+
+	return decodeConfig(configPath)
+}
+
+// LoadConfigFile loads a gitleaks-format configuration from path without
+// downloading or updating anything
+func LoadConfigFile(path string) (*Config, error) {
+	return decodeConfig(path)
+}
+
+// AllowlistFile holds suppressions kept apart from the gitleaks
+// configuration so updates don't overwrite them
+type AllowlistFile struct {
+	IgnoredFingerprints []string    `toml:"ignoredFingerprints,omitempty"`
+	Allowlists          []Allowlist `toml:"allowlists,omitempty"`
+}
+
+// LoadAllowlistFile loads global allowlists ([[allowlists]] tables) and
+// ignored finding fingerprints from a TOML file
+func LoadAllowlistFile(path string) (*AllowlistFile, error) {
+	var file AllowlistFile
+	meta, err := toml.DecodeFile(path, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse allowlist file: %v", err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("failed to parse allowlist file: unknown key %s", undecoded[0])
+	}
+	return &file, nil
+}
+
+// SaveAllowlistFile writes file to path as TOML, replacing its contents
+func SaveAllowlistFile(path string, file *AllowlistFile) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(file); err != nil {
+		return fmt.Errorf("failed to encode allowlist file: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write allowlist file: %v", err)
+	}
+	return nil
+}
+
+// LoadConfigInBackground loads the local configuration immediately and, if
+// an update check is due, runs it in the background so the scan isn't held
+// up. A downloaded update takes effect on the next run. Callers should Wait
+// on the returned Update (nil if no check was started) before exiting. The
+// configuration is still downloaded synchronously if it isn't present.
+func LoadConfigInBackground() (*Config, *Update, error) {
+	configDir, configPath, fileExists, err := prepareConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !fileExists {
+		cfg, err := LoadConfig(false)
+		return cfg, nil, err
+	}
 
-	// Load update info
 	updateInfo, err := loadUpdateInfo(configDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load update info: %v", err)
+		return nil, nil, fmt.Errorf("failed to load update info: %v", err)
 	}
 
+	cfg, err := decodeConfig(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !shouldCheckForUpdates(updateInfo, false) {
+		return cfg, nil, nil
+	}
+
+	update := &Update{done: make(chan struct{})}
+	go func() {
+		defer close(update.done)
+		update.err = checkForUpdates(configDir, configPath, updateInfo, false, io.Discard)
+	}()
+	return cfg, update, nil
+}
+
+// prepareConfig creates the configuration directory and returns it along
+// with the configuration file path and whether the file exists
+func prepareConfig() (configDir string, configPath string, fileExists bool, err error) {
+	// Get configuration directory
+	configDir, err = getConfigDir()
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to get config directory: %v", err)
+	}
+
+	// Create config directory if it doesn't exist
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", "", false, fmt.Errorf("failed to create config directory: %v", err)
+	}
+
+	configPath = filepath.Join(configDir, "gitleaks.toml")
+
 	// Check if file exists
-	fileExists := false
 	if _, err := os.Stat(configPath); err == nil {
 		fileExists = true
 	}
+	return configDir, configPath, fileExists, nil
+}
 
-	// If file exists and we should check for updates
-	if fileExists && shouldCheckForUpdates(updateInfo, forceUpdate) {
-		localHash, err := getLocalFileHash(configPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get local file hash: %v", err)
-		}
-
-		remoteHash, err := getRemoteFileHash(url)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get remote file hash: %v", err)
-		}
+// checkForUpdates compares the local configuration with the remote one,
+// downloads it if they differ (or forceUpdate is set), and records the check.
+// Progress messages are written to w.
+func checkForUpdates(configDir, configPath string, updateInfo *UpdateInfo, forceUpdate bool, w io.Writer) error {
+	localHash, err := getLocalFileHash(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to get local file hash: %v", err)
+	}
 
-		// If hashes are different or force update is true, update the file
-		if localHash != remoteHash || forceUpdate {
-			fmt.Println("Updating gitleaks configuration...")
-			if err := downloadGitleaksConfig(configPath); err != nil {
-				return nil, fmt.Errorf("failed to update gitleaks config: %v", err)
-			}
-			fmt.Println("Gitleaks configuration updated successfully")
-		}
+	remoteHash, err := getRemoteConfigHash()
+	if err != nil {
+		return fmt.Errorf("failed to get remote file hash: %w", err)
+	}
 
-		// Update the last check time and hash
-		updateInfo.LastCheck = time.Now()
-		updateInfo.LastHash = remoteHash
-		if err := saveUpdateInfo(configDir, updateInfo); err != nil {
-			return nil, fmt.Errorf("failed to save update info: %v", err)
-		}
-	} else if !fileExists {
-		// Download if file doesn't exist
+	// If hashes are different or force update is true, update the file
+	if localHash != remoteHash || forceUpdate {
+		fmt.Fprintln(w, "Updating gitleaks configuration...")
 		if err := downloadGitleaksConfig(configPath); err != nil {
-			return nil, err
+			return fmt.Errorf("failed to update gitleaks config: %w", err)
 		}
+		fmt.Fprintln(w, "Gitleaks configuration updated successfully")
+	}
+
+	// Update the last check time and hash
+	updateInfo.LastCheck = time.Now()
+	updateInfo.LastHash = remoteHash
+	if err := saveUpdateInfo(configDir, updateInfo); err != nil {
+		return fmt.Errorf("failed to save update info: %v", err)
 	}
+	return nil
+}
 
+// decodeConfig parses the configuration file at configPath and records
+// its rules for CompareRules
+func decodeConfig(configPath string) (*Config, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
 	var config Config
-	if _, err := toml.DecodeFile(configPath, &config); err != nil {
-		return nil, fmt.Errorf("failed to decode TOML: %v", err)
+	meta, err := toml.Decode(string(content), &config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConfigDecode, err)
+	}
+	config.useDefaultSet = meta.IsDefined("extend", "useDefault")
+	hash := sha256.Sum256(content)
+	config.Hash = hex.EncodeToString(hash[:])
+	if err := recordRules(config.Hash, content); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to record rules: %v\n", err)
 	}
 	return &config, nil
 }
 
-// downloadGitleaksConfig downloads the official Gitleaks configuration
+// downloadGitleaksConfig downloads the official Gitleaks configuration.
+// The configuration is only replaced once it's fully downloaded and
+// decodes, so an interrupted update leaves the previous one in place.
 func downloadGitleaksConfig(configPath string) error {
-	url := "https://raw.githubusercontent.com/gitleaks/gitleaks/master/config/gitleaks.toml"
-	resp, err := http.Get(url)
+	content, err := fetchConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(configPath, content); err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so path never holds a partial write
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
 	if err != nil {
-		return fmt.Errorf("failed to download TOML: %v", err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// fetchConfig downloads the configuration from configURL, or from the
+// first of the Mirrors that serves it, retrying each with backoff
+func fetchConfig() ([]byte, error) {
+	var lastErr error
+	for _, url := range append([]string{configURL}, Mirrors...) {
+		backoff := downloadBackoff
+		for attempt := 1; attempt <= downloadAttempts; attempt++ {
+			content, err := fetchConfigURL(url)
+			if err == nil {
+				return content, nil
+			}
+			lastErr = err
+			if attempt < downloadAttempts {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %v", ErrConfigDownload, lastErr)
+}
+
+// fetchConfigURL downloads the configuration from url, rejecting error
+// responses and content that isn't valid TOML, such as a captive portal's
+// login page
+func fetchConfigURL(url string) ([]byte, error) {
+	resp, err := downloadClient.Get(url)
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	out, err := os.Create(configPath)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %v", err)
+		return nil, fmt.Errorf("failed to read %s: %v", url, err)
 	}
-	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
-	return err
+	var config Config
+	if _, err := toml.Decode(string(content), &config); err != nil {
+		return nil, fmt.Errorf("%s didn't return a valid configuration: %v", url, err)
+	}
+	return content, nil
 }
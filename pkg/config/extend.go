@@ -0,0 +1,36 @@
+package config
+
+// DropsDefault checks if the configuration sets [extend] useDefault = false
+// explicitly, asking for the default rules to be left out when it extends
+// them
+func (c *Config) DropsDefault() bool {
+	return c.useDefaultSet && !c.Extend.UseDefault
+}
+
+// Merge returns base extended by ext, the way gitleaks extends a
+// configuration: ext's rules replace base rules with the same ID and are
+// added after them otherwise, and allowlists, disabled rules, and ignored
+// fingerprints are combined. The result keeps base's hash unless base has
+// no rules.
+func Merge(base, ext *Config) *Config {
+	merged := &Config{Title: base.Title, Extend: base.Extend, Hash: base.Hash}
+	if len(base.Rules) == 0 {
+		merged.Hash = ext.Hash
+	}
+
+	replaced := make(map[string]bool, len(ext.Rules))
+	for _, rule := range ext.Rules {
+		replaced[rule.ID] = true
+	}
+	for _, rule := range base.Rules {
+		if !replaced[rule.ID] {
+			merged.Rules = append(merged.Rules, rule)
+		}
+	}
+	merged.Rules = append(merged.Rules, ext.Rules...)
+
+	merged.Allowlists = append(append([]Allowlist{}, base.Allowlists...), ext.Allowlists...)
+	merged.Extend.DisabledRules = append(append([]string{}, base.Extend.DisabledRules...), ext.Extend.DisabledRules...)
+	merged.IgnoredFingerprints = append(append([]string{}, base.IgnoredFingerprints...), ext.IgnoredFingerprints...)
+	return merged
+}
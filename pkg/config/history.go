@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// minHashPrefix is the shortest hash prefix LoadRecordedConfig accepts
+const minHashPrefix = 7
+
+// getRulesDir returns the directory holding a copy of each configuration
+// loaded, named by its hash
+func getRulesDir() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %v", err)
+	}
+	return filepath.Join(configDir, "rules"), nil
+}
+
+// recordRules keeps a copy of a loaded configuration under its hash, so
+// later runs can compare their rules with it
+func recordRules(hash string, content []byte) error {
+	rulesDir, err := getRulesDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(rulesDir, hash+".toml")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(rulesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create rules directory: %v", err)
+	}
+	return writeFileAtomic(path, content)
+}
+
+// LoadRecordedConfig loads a configuration recorded by an earlier run by
+// its hash, as reported in config_hash, or a unique prefix of at least 7
+// characters
+func LoadRecordedConfig(hash string) (*Config, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if len(hash) < minHashPrefix {
+		return nil, fmt.Errorf("config hash %q is too short, give at least %d characters", hash, minHashPrefix)
+	}
+	rulesDir, err := getRulesDir()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(rulesDir, filepath.Base(hash)+"*.toml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recorded rules: %v", err)
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no rules recorded for config hash %s", hash)
+	case 1:
+	default:
+		return nil, fmt.Errorf("config hash %s is ambiguous, matching %d recorded configurations", hash, len(matches))
+	}
+
+	var config Config
+	if _, err := toml.DecodeFile(matches[0], &config); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConfigDecode, err)
+	}
+	config.Hash = strings.TrimSuffix(filepath.Base(matches[0]), ".toml")
+	return &config, nil
+}
+
+// RulesDiff lists the rule IDs that differ between two configurations
+type RulesDiff struct {
+	OldHash string   `json:"old_hash"`
+	NewHash string   `json:"new_hash"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	// Changed rules have the same ID but a different regex, keywords,
+	// entropy, allowlists, or other setting
+	Changed []string `json:"changed"`
+}
+
+// Empty checks if the configurations have the same rules
+func (d RulesDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// CompareRules diffs the rules of newConfig against oldConfig, listing
+// each kind of change sorted by rule ID
+func CompareRules(oldConfig, newConfig *Config) RulesDiff {
+	diff := RulesDiff{
+		OldHash: oldConfig.Hash,
+		NewHash: newConfig.Hash,
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []string{},
+	}
+	oldRules := make(map[string]Rule, len(oldConfig.Rules))
+	for _, rule := range oldConfig.Rules {
+		oldRules[rule.ID] = rule
+	}
+	newRules := make(map[string]bool, len(newConfig.Rules))
+	for _, rule := range newConfig.Rules {
+		newRules[rule.ID] = true
+		old, ok := oldRules[rule.ID]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, rule.ID)
+		case !reflect.DeepEqual(old, rule):
+			diff.Changed = append(diff.Changed, rule.ID)
+		}
+	}
+	for id := range oldRules {
+		if !newRules[id] {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+func TestGlobalAllowlistCondition(t *testing.T) {
+	rule := config.Rule{ID: "test-rule", Regex: `key=(\w+)`, SecretGroup: 1}
+	tests := []struct {
+		name      string
+		condition string
+		secret    string
+		want      bool
+	}{
+		{"AND with both checks matching", "AND", "zq81Xk4Lp0WmTESTKEY", true},
+		{"AND with only the regex matching", "AND", "zq81Xk4Lp0Wm", false},
+		{"AND with only the stopword matching", "AND", "Ab81Xk4Lp0WmTESTKEY", false},
+		{"OR with only the regex matching", "OR", "zq81Xk4Lp0Wm", true},
+		{"default with only the regex matching", "", "zq81Xk4Lp0Wm", true},
+		{"OR with neither matching", "OR", "Ab81Xk4Lp0Wm", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Rules: []config.Rule{rule},
+				Allowlists: []config.Allowlist{{
+					Regexes:   []string{`^zq`},
+					Stopwords: []string{"TESTKEY"},
+					Condition: tt.condition,
+				}},
+			}
+			s := New(cfg, Options{})
+			match := "key=" + tt.secret
+			if got := s.isAllowlisted(match, tt.secret, match, "https://example.com/app.js", rule); got != tt.want {
+				t.Errorf("isAllowlisted(%q) = %v, want %v", tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleAllowlistCondition(t *testing.T) {
+	rule := config.Rule{
+		ID:          "test-rule",
+		Regex:       `key=(\w+)`,
+		SecretGroup: 1,
+		Allowlists: []config.Allowlist{{
+			Regexes:   []string{`^zq`},
+			Stopwords: []string{"TESTKEY"},
+			Condition: "AND",
+		}},
+	}
+	s := New(&config.Config{Rules: []config.Rule{rule}}, Options{})
+	if s.isAllowlisted("key=zq81Xk4Lp0Wm", "zq81Xk4Lp0Wm", "key=zq81Xk4Lp0Wm", "https://example.com/app.js", rule) {
+		t.Error("rule allowlist with AND suppressed a match only its regex matched")
+	}
+	if !s.isAllowlisted("key=zq81Xk4Lp0WmTESTKEY", "zq81Xk4Lp0WmTESTKEY", "key=zq81Xk4Lp0WmTESTKEY", "https://example.com/app.js", rule) {
+		t.Error("rule allowlist with AND didn't suppress a match both checks matched")
+	}
+}
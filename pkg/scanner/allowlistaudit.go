@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// AllowlistSuppression records a candidate finding an allowlist or filter
+// suppressed, collected with ExplainAllowlist
+type AllowlistSuppression struct {
+	// Allowlist names what suppressed the candidate, such as
+	// "global allowlist 2 (Test fixtures)" or "example secret"
+	Allowlist string `json:"allowlist"`
+	RuleID    string `json:"rule_id"`
+	File      string `json:"file"`
+	Secret    string `json:"secret"`
+	// Count is how many times the candidate was suppressed in File
+	Count int `json:"count"`
+}
+
+// allowlistName names the index'th allowlist of kind, 1-based as in
+// configuration errors, with its description when it has one
+func allowlistName(kind string, index int, allowlist config.Allowlist) string {
+	name := fmt.Sprintf("%s %d", kind, index+1)
+	if allowlist.Description != "" {
+		name += " (" + allowlist.Description + ")"
+	}
+	return name
+}
+
+// addSuppression records a suppressed candidate, counting repeats
+func (s *Scanner) addSuppression(suppression AllowlistSuppression) {
+	key := suppression.Allowlist + "\x00" + suppression.RuleID + "\x00" + suppression.File + "\x00" + suppression.Secret
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.suppressions == nil {
+		s.suppressions = make(map[string]*AllowlistSuppression)
+	}
+	if existing, ok := s.suppressions[key]; ok {
+		existing.Count++
+		return
+	}
+	suppression.Count = 1
+	s.suppressions[key] = &suppression
+}
+
+// GetAllowlistAudit returns the candidates suppressed during the scan,
+// grouped by what suppressed them
+func (s *Scanner) GetAllowlistAudit() []AllowlistSuppression {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var audit []AllowlistSuppression
+	for _, suppression := range s.suppressions {
+		audit = append(audit, *suppression)
+	}
+	sort.Slice(audit, func(i, j int) bool {
+		a, b := audit[i], audit[j]
+		if a.Allowlist != b.Allowlist {
+			return a.Allowlist < b.Allowlist
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Secret < b.Secret
+	})
+	return audit
+}
+
+// PrintAllowlistAudit writes a table of the suppressed candidates to w,
+// with their secrets redacted
+func (s *Scanner) PrintAllowlistAudit(w io.Writer) error {
+	audit := s.GetAllowlistAudit()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nSUPPRESSED BY\tRULE\tFILE\tSECRET\tCOUNT")
+	for _, suppression := range audit {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n",
+			suppression.Allowlist, suppression.RuleID, suppression.File, redactSecret(suppression.Secret), suppression.Count)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write allowlist audit: %v", err)
+	}
+	if _, err := fmt.Fprintf(w, "%d %s suppressed\n", len(audit), plural(len(audit), "candidate")); err != nil {
+		return fmt.Errorf("failed to write allowlist audit: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// pageAttribute is an inline event handler or data-* attribute of a page
+// element
+type pageAttribute struct {
+	// Ref names the page, element, and attribute, such as
+	// "https://example.com/ button#buy[onclick]", and is used as the file
+	// of its findings
+	Ref   string
+	Value string
+}
+
+// attributesScript collects the non-empty on* and data-* attributes of
+// every element as [selector, name, value] triples. The selector is built
+// like elementSelector's.
+const attributesScript = `() => {
+	const selector = el => {
+		const parts = [];
+		for (; el && el.nodeType === 1; el = el.parentElement) {
+			if (el.id) {
+				parts.unshift(el.localName + '#' + el.id);
+				break;
+			}
+			let part = el.localName;
+			const parent = el.parentElement;
+			if (parent) {
+				const same = Array.from(parent.children).filter(c => c.localName === el.localName);
+				if (same.length > 1) {
+					part += ':nth-of-type(' + (same.indexOf(el) + 1) + ')';
+				}
+			}
+			parts.unshift(part);
+		}
+		return parts.join(' > ');
+	};
+	const found = [];
+	for (const el of document.querySelectorAll('*')) {
+		for (const attr of el.attributes) {
+			const name = attr.name.toLowerCase();
+			if ((name.startsWith('on') || name.startsWith('data-')) && attr.value.trim()) {
+				found.push([selector(el), name, attr.value]);
+			}
+		}
+	}
+	return found;
+}`
+
+// isScannedAttribute checks if an attribute is an inline event handler or
+// a data-* attribute
+func isScannedAttribute(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "on") || strings.HasPrefix(name, "data-")
+}
+
+// attributeRef names an element's attribute on pageURL
+func attributeRef(pageURL string, selector string, name string) string {
+	return fmt.Sprintf("%s %s[%s]", pageURL, selector, name)
+}
+
+// elementSelector returns a CSS selector for n: its tag and id, or its path
+// from the closest ancestor with an id (or the root) using :nth-of-type
+// where siblings share a tag
+func elementSelector(n *html.Node) string {
+	var parts []string
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if id, ok := htmlAttr(n, "id"); ok && id != "" {
+			parts = append(parts, n.Data+"#"+id)
+			break
+		}
+		part := n.Data
+		if n.Parent != nil {
+			index, same := 0, 0
+			for sibling := n.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+				if sibling.Type == html.ElementNode && sibling.Data == n.Data {
+					same++
+					if sibling == n {
+						index = same
+					}
+				}
+			}
+			if same > 1 {
+				part += fmt.Sprintf(":nth-of-type(%d)", index)
+			}
+		}
+		parts = append(parts, part)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+// nodeAttributes returns the scanned attributes of element n on pageURL
+func nodeAttributes(pageURL string, n *html.Node) []pageAttribute {
+	var attributes []pageAttribute
+	for _, attr := range n.Attr {
+		if isScannedAttribute(attr.Key) && strings.TrimSpace(attr.Val) != "" {
+			attributes = append(attributes, pageAttribute{
+				Ref:   attributeRef(pageURL, elementSelector(n), strings.ToLower(attr.Key)),
+				Value: attr.Val,
+			})
+		}
+	}
+	return attributes
+}
+
+// evaluatedAttributes converts the result of attributesScript on pageURL
+func evaluatedAttributes(pageURL string, result interface{}) []pageAttribute {
+	values, _ := result.([]interface{})
+	var attributes []pageAttribute
+	for _, value := range values {
+		triple, ok := value.([]interface{})
+		if !ok || len(triple) != 3 {
+			continue
+		}
+		selector, _ := triple[0].(string)
+		name, _ := triple[1].(string)
+		attrValue, _ := triple[2].(string)
+		attributes = append(attributes, pageAttribute{Ref: attributeRef(pageURL, selector, name), Value: attrValue})
+	}
+	return attributes
+}
+
+// addAttributes queues attributes found on a page to be scanned with its
+// files
+func (s *Scanner) addAttributes(attributes []pageAttribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pendingAttributes = append(s.pendingAttributes, attributes...)
+}
+
+// checkAttributes scans the queued attribute values for secrets,
+// attributing findings to target
+func (s *Scanner) checkAttributes(target string) {
+	s.mu.Lock()
+	attributes := s.pendingAttributes
+	s.pendingAttributes = nil
+	s.mu.Unlock()
+
+	if len(attributes) > 0 {
+		s.debugf("Scanning %d inline attributes", len(attributes))
+	}
+	for _, attribute := range attributes {
+		if s.limitReached() {
+			return
+		}
+		s.checkContent(attribute.Ref, target, "", []byte(attribute.Value))
+	}
+}
@@ -0,0 +1,168 @@
+package scanner
+
+import (
+	"strings"
+)
+
+// beautifyLineLength is the line length from which a snippet is taken to
+// be minified and reflowed by BeautifySnippets
+const beautifyLineLength = 200
+
+// beautifySnippet reflows a snippet of minified JavaScript for reading,
+// breaking lines after ; and { and around }, and indenting by brace depth.
+// String literals and match itself are kept intact so the secret still
+// appears verbatim. It's a heuristic, not a parser: regex literals and
+// snippets starting inside a string can throw it off. Snippets without a
+// long line are returned as is.
+func beautifySnippet(snippet string, match string) string {
+	if !hasLongLine(snippet, beautifyLineLength) {
+		return snippet
+	}
+
+	f := &snippetFormatter{lineStart: true}
+	if i := strings.Index(snippet, match); i >= 0 && match != "" {
+		f.write(snippet[:i], true)
+		f.write(match, false)
+		f.write(snippet[i+len(match):], true)
+	} else {
+		f.write(snippet, true)
+	}
+	return strings.TrimRight(f.b.String(), " \n")
+}
+
+// hasLongLine checks if text has a line of at least length bytes
+func hasLongLine(text string, length int) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if len(line) >= length {
+			return true
+		}
+	}
+	return false
+}
+
+// snippetFormatter is the state of beautifySnippet
+type snippetFormatter struct {
+	b     strings.Builder
+	depth int
+	// parens counts the open parentheses within the current braces, and
+	// outerParens those of the enclosing braces
+	parens      int
+	outerParens []int
+	quote       byte
+	escaped     bool
+	lineStart   bool
+	// opened is set after a { and closed after a }, whose line breaks
+	// depend on what follows
+	opened bool
+	closed bool
+}
+
+// write adds text, reflowing it if reflow is set. Text written without
+// reflow is only indented, so it stays intact.
+func (f *snippetFormatter) write(text string, reflow bool) {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		// Copy string literals as they are
+		if f.quote != 0 {
+			f.emit(c)
+			switch {
+			case f.escaped:
+				f.escaped = false
+			case c == '\\':
+				f.escaped = true
+			case c == f.quote:
+				f.quote = 0
+			}
+			continue
+		}
+
+		if reflow && (f.lineStart || f.opened || f.closed) && (c == ' ' || c == '\t' || c == '\n') {
+			continue
+		}
+		// Keep empty braces together
+		if f.opened {
+			f.opened = false
+			if c == '}' {
+				f.closeBrace()
+				f.emit(c)
+				f.closed = true
+				continue
+			}
+			if reflow {
+				f.newline()
+			}
+		}
+		if f.closed {
+			f.closed = false
+			if reflow && !strings.ContainsRune(";,.()]", rune(c)) {
+				f.newline()
+			}
+		}
+
+		switch c {
+		case '"', '\'', '`':
+			f.quote = c
+			f.emit(c)
+		case '(':
+			f.parens++
+			f.emit(c)
+		case ')':
+			if f.parens > 0 {
+				f.parens--
+			}
+			f.emit(c)
+		case '{':
+			f.emit(c)
+			f.depth++
+			f.outerParens = append(f.outerParens, f.parens)
+			f.parens = 0
+			f.opened = true
+		case '}':
+			f.closeBrace()
+			if reflow && !f.lineStart {
+				f.newline()
+			}
+			f.emit(c)
+			f.closed = true
+		case ';':
+			f.emit(c)
+			if reflow && f.parens == 0 {
+				f.newline()
+			}
+		case '\n':
+			f.newline()
+		default:
+			f.emit(c)
+		}
+	}
+}
+
+// closeBrace leaves the current braces
+func (f *snippetFormatter) closeBrace() {
+	if f.depth > 0 {
+		f.depth--
+	}
+	f.parens = 0
+	if n := len(f.outerParens); n > 0 {
+		f.parens = f.outerParens[n-1]
+		f.outerParens = f.outerParens[:n-1]
+	}
+}
+
+// emit writes c, indenting it first if it starts a line
+func (f *snippetFormatter) emit(c byte) {
+	if f.lineStart {
+		f.b.WriteString(strings.Repeat("  ", f.depth))
+		f.lineStart = false
+	}
+	f.b.WriteByte(c)
+}
+
+// newline ends the current line, unless it's empty
+func (f *snippetFormatter) newline() {
+	if !f.lineStart {
+		f.b.WriteByte('\n')
+		f.lineStart = true
+	}
+}
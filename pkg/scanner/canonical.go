@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"strings"
+)
+
+// CanonicalURL returns rawURL with a leading "www." removed from its host,
+// so a site's www and apex hostnames compare equal. URLs without a scheme
+// and hosts that would be left without a dot (www.com) are returned as is.
+func CanonicalURL(rawURL string) string {
+	i := strings.Index(rawURL, "://")
+	if i < 0 {
+		return rawURL
+	}
+	start := i + len("://")
+	rest := rawURL[start:]
+	if len(rest) < 4 || !strings.EqualFold(rest[:4], "www.") {
+		return rawURL
+	}
+	host := rest[4:]
+	if end := strings.IndexAny(host, "/?#: "); end >= 0 {
+		host = host[:end]
+	}
+	if !strings.Contains(host, ".") {
+		return rawURL
+	}
+	return rawURL[:start] + rest[4:]
+}
+
+// canonicalizeFinding rewrites the hosts of finding's file, target, and
+// aliases with CanonicalURL
+func canonicalizeFinding(finding *Finding) {
+	finding.File = CanonicalURL(finding.File)
+	finding.Target = CanonicalURL(finding.Target)
+	if len(finding.Aliases) > 0 {
+		aliases := make([]string, len(finding.Aliases))
+		for i, alias := range finding.Aliases {
+			aliases[i] = CanonicalURL(alias)
+		}
+		finding.Aliases = aliases
+	}
+}
+
+// dropMerged drops findings already reported for the same target with the
+// same fingerprint, as happens when the www and apex hostnames of a site
+// serve the same files. The caller must hold s.mu.
+func (s *Scanner) dropMerged(findings []Finding) []Finding {
+	if s.mergedFindings == nil {
+		s.mergedFindings = make(map[string]bool)
+	}
+	kept := findings[:0]
+	for _, finding := range findings {
+		key := finding.Target + "\x00" + finding.Fingerprint
+		if s.mergedFindings[key] {
+			s.debugf("Merged %s finding in %s with the one already reported", finding.RuleID, finding.File)
+			continue
+		}
+		s.mergedFindings[key] = true
+		kept = append(kept, finding)
+	}
+	return kept
+}
+
+// pageKey returns the key identifying pageURL when deduplicating pages
+func (s *Scanner) pageKey(pageURL string) string {
+	if s.opts.CanonicalizeHost {
+		return CanonicalURL(pageURL)
+	}
+	return pageURL
+}
@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// textEncoding returns the encoding of a text response body starting with
+// head, or nil if it's UTF-8 without a byte order mark or in an unknown
+// charset. A byte order mark takes precedence over the charset in
+// contentType, as in browsers.
+func textEncoding(head []byte, contentType string) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	charset := strings.ToLower(strings.TrimSpace(params["charset"]))
+	if charset == "" || charset == "utf-8" || charset == "utf8" {
+		return nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil
+	}
+	return enc
+}
+
+// toUTF8 transcodes a text response body to UTF-8 so rules match it,
+// returning bodies already in UTF-8 or an unknown charset unchanged
+func toUTF8(content []byte, contentType string) []byte {
+	enc := textEncoding(content, contentType)
+	if enc == nil {
+		return content
+	}
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return content
+	}
+	return decoded
+}
+
+// utf8Reader is toUTF8 for a streamed body
+func utf8Reader(body io.Reader, contentType string) io.Reader {
+	buffered := bufio.NewReader(body)
+	head, _ := buffered.Peek(3)
+	enc := textEncoding(head, contentType)
+	if enc == nil {
+		return buffered
+	}
+	return transform.NewReader(buffered, enc.NewDecoder())
+}
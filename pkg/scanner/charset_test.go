@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     []byte
+		contentType string
+		want        string
+	}{
+		{"UTF-8 unchanged", []byte("const s = \"é\";"), "application/javascript", "const s = \"é\";"},
+		{"Latin-1 charset", []byte("const s = \"\xe9\";"), "application/javascript; charset=iso-8859-1", "const s = \"é\";"},
+		{"windows-1252 charset", []byte("const s = \"\x80\";"), "text/javascript; charset=windows-1252", "const s = \"€\";"},
+		{"UTF-8 BOM", []byte("\xef\xbb\xbfconst s = 1;"), "application/javascript", "const s = 1;"},
+		{"UTF-16LE BOM over charset", []byte("\xff\xfea\x00=\x001\x00"), "application/javascript; charset=iso-8859-1", "a=1"},
+		{"unknown charset unchanged", []byte("const s = 1;"), "application/javascript; charset=x-unknown", "const s = 1;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(toUTF8(tt.content, tt.contentType)); got != tt.want {
+				t.Errorf("toUTF8() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFileLatin1(t *testing.T) {
+	content, err := os.ReadFile("testdata/latin1.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Rules: []config.Rule{{
+		ID:          "test-token",
+		Regex:       `apiToken\s*=\s*"([A-Za-z0-9]{16})"`,
+		SecretGroup: 1,
+	}}}
+	fetcher := stubFetcher{
+		bodies:       map[string]string{"https://example.com/latin1.js": string(content)},
+		contentTypes: map[string]string{"https://example.com/latin1.js": "application/javascript; charset=iso-8859-1"},
+	}
+	s := New(cfg, Options{Fetcher: fetcher, ContextLines: 1})
+	if err := s.checkFile(context.Background(), "https://example.com/latin1.js", "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+
+	findings := s.GetFindings()
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	if findings[0].Secret != "Zq81Xk4Lp0WmR7tY" {
+		t.Errorf("secret = %q, want Zq81Xk4Lp0WmR7tY", findings[0].Secret)
+	}
+	if !strings.Contains(findings[0].CodeSnippet, "Clé de l'API © société") || !strings.Contains(findings[0].CodeSnippet, "déjà utilisé") {
+		t.Errorf("snippet wasn't transcoded to UTF-8: %q", findings[0].CodeSnippet)
+	}
+}
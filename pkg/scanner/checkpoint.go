@@ -0,0 +1,155 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// checkpointState is the progress of a scan saved to the Checkpoint file:
+// the pages and JavaScript files fully scanned and the findings from them
+type checkpointState struct {
+	Pages    []string  `json:"pages"`
+	Files    []string  `json:"files"`
+	Findings []Finding `json:"findings"`
+}
+
+// checkpoint tracks the pages and files (by dedupe key) completed in this
+// and earlier runs
+type checkpoint struct {
+	pages map[string]bool
+	files map[string]bool
+}
+
+// loadCheckpoint restores the progress saved in the Checkpoint file, if
+// any, so completed pages and files are skipped and their findings kept
+func (s *Scanner) loadCheckpoint() error {
+	if s.opts.Checkpoint == "" {
+		return nil
+	}
+	s.checkpoint = &checkpoint{pages: make(map[string]bool), files: make(map[string]bool)}
+
+	data, err := os.ReadFile(s.opts.Checkpoint)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	var state checkpointState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse checkpoint %s: %v", s.opts.Checkpoint, err)
+	}
+
+	for _, page := range state.Pages {
+		s.checkpoint.pages[page] = true
+	}
+	for _, file := range state.Files {
+		s.checkpoint.files[file] = true
+	}
+	s.markSeen(state.Files)
+
+	s.mu.Lock()
+	s.findings = append(s.findings, state.Findings...)
+	s.foundFindings += len(state.Findings)
+	s.mu.Unlock()
+	if s.opts.OnFinding != nil {
+		for _, finding := range state.Findings {
+			finding := finding
+			s.callHook("OnFinding", func() { s.opts.OnFinding(finding) })
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Resuming from checkpoint %s: %d pages and %d files already scanned\n", s.opts.Checkpoint, len(state.Pages), len(state.Files))
+	return nil
+}
+
+// pageDone checks if the checkpoint records pageURL as fully scanned
+func (s *Scanner) pageDone(pageURL string) bool {
+	return s.checkpoint != nil && s.checkpoint.pages[pageURL]
+}
+
+// pendingFiles drops the files the checkpoint records as scanned
+func (s *Scanner) pendingFiles(files []string) []string {
+	if s.checkpoint == nil {
+		return files
+	}
+	var pending []string
+	for _, file := range files {
+		if s.checkpoint.files[s.dedupeKey(file)] {
+			s.debugf("Skipping %s, scanned before the checkpoint", file)
+			continue
+		}
+		pending = append(pending, file)
+	}
+	return pending
+}
+
+// saveCheckpoint records files, and pageURL if not empty, as completed and
+// rewrites the checkpoint file. It's only called between batches of files,
+// so the saved findings are exactly those of the completed work. Failures
+// are warned about without stopping the scan.
+func (s *Scanner) saveCheckpoint(pageURL string, files []string) {
+	if s.checkpoint == nil {
+		return
+	}
+	if pageURL != "" {
+		s.checkpoint.pages[pageURL] = true
+	}
+	for _, file := range files {
+		s.checkpoint.files[s.dedupeKey(file)] = true
+	}
+
+	s.mu.Lock()
+	state := checkpointState{
+		Pages:    sortedKeys(s.checkpoint.pages),
+		Files:    sortedKeys(s.checkpoint.files),
+		Findings: append([]Finding{}, s.findings...),
+	}
+	s.mu.Unlock()
+
+	if err := writeCheckpoint(s.opts.Checkpoint, state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// writeCheckpoint replaces the checkpoint file at path with state. It writes
+// a temporary file first and renames it over path, so an interrupted write
+// leaves the previous checkpoint intact. Findings include raw secrets, so
+// only the owner can read it.
+func writeCheckpoint(path string, state checkpointState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	return nil
+}
+
+// removeCheckpoint deletes the checkpoint file once the scan has completed
+func (s *Scanner) removeCheckpoint() {
+	if s.checkpoint == nil {
+		return
+	}
+	if err := os.Remove(s.opts.Checkpoint); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to remove checkpoint: %v\n", err)
+	}
+}
+
+// sortedKeys returns the keys of set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
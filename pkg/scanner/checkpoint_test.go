@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// failingFetcher fails every request to a URL in failing and serves the
+// rest from stub
+type failingFetcher struct {
+	stub    stubFetcher
+	failing map[string]bool
+}
+
+func (f failingFetcher) Do(req *http.Request) (*http.Response, error) {
+	if f.failing[req.URL.String()] {
+		return nil, errors.New("connection reset")
+	}
+	return f.stub.Do(req)
+}
+
+func TestCheckpointRecordsOnlyCheckedFiles(t *testing.T) {
+	fetcher := failingFetcher{
+		stub:    stubFetcher{bodies: map[string]string{"https://example.com/app.js": "console.log('hello');\n"}},
+		failing: map[string]bool{"https://example.com/broken.js": true},
+	}
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	s := New(&config.Config{}, Options{Fetcher: fetcher, Checkpoint: path})
+	if err := s.loadCheckpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []string{"https://example.com/app.js", "https://example.com/broken.js"}
+	checked, complete := s.checkFiles(context.Background(), "https://example.com/", files, 2)
+	if complete {
+		t.Error("checkFiles reported a complete batch although a file failed")
+	}
+	if want := []string{"https://example.com/app.js"}; !reflect.DeepEqual(checked, want) {
+		t.Fatalf("checked = %v, want %v", checked, want)
+	}
+	s.saveCheckpoint("", checked)
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+	resumed := New(&config.Config{}, Options{Fetcher: fetcher, Checkpoint: path})
+	if err := resumed.loadCheckpoint(); err != nil {
+		t.Fatal(err)
+	}
+	if pending, want := resumed.pendingFiles(files), []string{"https://example.com/broken.js"}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("pending = %v, want %v", pending, want)
+	}
+}
@@ -0,0 +1,166 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// webpackChunkMapRegex matches webpack chunk filename expressions such as
+	// "static/js/"+e+"."+{12:"a1b2c3"}[e]+".chunk.js", with or without the
+	// chunk ID before the hash
+	webpackChunkMapRegex = regexp.MustCompile(`["']([^"'\s]*)["']\s*\+\s*(?:([\w$]+)\s*\+\s*["']([^"'\s]*)["']\s*\+\s*)?\{([^{}]*)\}\s*\[[\w$]+\]\s*\+\s*["']([^"'\s]*\.js)["']`)
+	// webpackChunkEntryRegex matches the id:"hash" entries of a chunk map
+	webpackChunkEntryRegex = regexp.MustCompile(`([\w$]+|"[^"]*"|'[^']*')\s*:\s*["']([^"']+)["']`)
+	// webpackChunkNameMapRegex matches webpack chunk filename expressions
+	// with named chunks, such as
+	// "js/"+({7:"vendors"}[e]||e)+"."+{7:"a1b2c3",9:"d4e5f6"}[e]+".js"
+	webpackChunkNameMapRegex = regexp.MustCompile(`["']([^"'\s]*)["']\s*\+\s*\(\s*\{([^{}]*)\}\s*\[[\w$]+\]\s*\|\|\s*[\w$]+\s*\)\s*\+\s*["']([^"'\s]*)["']\s*\+\s*\{([^{}]*)\}\s*\[[\w$]+\]\s*\+\s*["']([^"'\s]*\.js)["']`)
+	// webpackChunkFuncRegex matches a __webpack_require__.u that builds
+	// chunk filenames from the ID alone, such as e=>"js/"+e+".chunk.js"
+	webpackChunkFuncRegex = regexp.MustCompile(`\.u\s*=\s*(?:function\s*\(\s*([\w$]+)\s*\)\s*\{\s*return|\(?\s*([\w$]+)\s*\)?\s*=>)\s*["']([^"'\s]*)["']\s*\+\s*([\w$]+)\s*\+\s*["']([^"'\s]*\.js)["']`)
+	// webpackChunkLoadRegex matches the chunk IDs loaded with
+	// __webpack_require__.e(id)
+	webpackChunkLoadRegex = regexp.MustCompile(`\.e\(\s*(\d+|"[^"]+"|'[^']+')\s*\)`)
+	// webpackPublicPathRegex matches the public path assigned by the runtime
+	webpackPublicPathRegex = regexp.MustCompile(`__webpack_require__\.p\s*=\s*["']([^"']*)["']|\.p\s*=\s*["'](/[^"']*)["']`)
+	// jsLiteralRegex matches string literals naming a JavaScript file
+	jsLiteralRegex = regexp.MustCompile(`["'\x60]([^"'\x60\s<>()]+\.m?js)["'\x60]`)
+)
+
+// extractChunkURLs returns the URLs of JavaScript chunks referenced by the
+// file at fileURL, from webpack chunk maps and chunk filename functions and
+// from string literals ending in .js. Paths are resolved against the
+// webpack public path if one is found, and against the file's URL otherwise.
+func extractChunkURLs(fileURL string, content string) []string {
+	base, err := url.Parse(fileURL)
+	if err != nil {
+		return nil
+	}
+
+	// Chunk paths are relative to the public path, usually the site root
+	chunkBase := base
+	if match := webpackPublicPathRegex.FindStringSubmatch(content); match != nil {
+		publicPath := match[1] + match[2]
+		if publicPath != "" {
+			if ref, err := url.Parse(publicPath); err == nil {
+				chunkBase = base.ResolveReference(ref)
+			}
+		}
+	}
+
+	var chunks []string
+	seen := make(map[string]bool)
+	add := func(resolveBase *url.URL, ref string) {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			return
+		}
+		chunk, err := normalizeJSURL(resolveBase, ref, false)
+		if err != nil {
+			return
+		}
+		if chunk != fileURL && !seen[chunk] {
+			seen[chunk] = true
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	for _, match := range webpackChunkNameMapRegex.FindAllStringSubmatch(content, -1) {
+		prefix, names, separator, hashes, suffix := match[1], match[2], match[3], match[4], match[5]
+		nameOf := make(map[string]string)
+		for _, entry := range webpackChunkEntryRegex.FindAllStringSubmatch(names, -1) {
+			nameOf[strings.Trim(entry[1], `"'`)] = entry[2]
+		}
+		for _, entry := range webpackChunkEntryRegex.FindAllStringSubmatch(hashes, -1) {
+			id := strings.Trim(entry[1], `"'`)
+			name, ok := nameOf[id]
+			if !ok {
+				name = id
+			}
+			add(chunkBase, prefix+name+separator+entry[2]+suffix)
+		}
+	}
+	// The hash map of a named expression also reads as an unnamed one
+	unnamed := webpackChunkNameMapRegex.ReplaceAllString(content, "")
+
+	for _, match := range webpackChunkMapRegex.FindAllStringSubmatch(unnamed, -1) {
+		prefix, idVar, separator, entries, suffix := match[1], match[2], match[3], match[4], match[5]
+		for _, entry := range webpackChunkEntryRegex.FindAllStringSubmatch(entries, -1) {
+			name := prefix + entry[2] + suffix
+			if idVar != "" {
+				name = prefix + strings.Trim(entry[1], `"'`) + separator + entry[2] + suffix
+			}
+			add(chunkBase, name)
+		}
+	}
+
+	// Without a map, take the chunk IDs from the chunks the file loads
+	for _, match := range webpackChunkFuncRegex.FindAllStringSubmatch(content, -1) {
+		param, prefix, idVar, suffix := match[1]+match[2], match[3], match[4], match[5]
+		if idVar != param {
+			continue
+		}
+		for _, load := range webpackChunkLoadRegex.FindAllStringSubmatch(content, -1) {
+			add(chunkBase, prefix+strings.Trim(load[1], `"'`)+suffix)
+		}
+	}
+
+	for _, match := range jsLiteralRegex.FindAllStringSubmatch(content, -1) {
+		ref := match[1]
+		// Skip filename fragments like ".chunk.js" used to build chunk names
+		if strings.HasPrefix(path.Base(ref), ".") {
+			continue
+		}
+		resolveBase := base
+		if !strings.HasPrefix(ref, "/") && !strings.HasPrefix(ref, ".") && !strings.Contains(ref, "://") {
+			resolveBase = chunkBase
+		}
+		add(resolveBase, ref)
+	}
+
+	return chunks
+}
+
+// addChunks queues chunk URLs referenced by a scanned file that haven't
+// been checked or queued yet
+func (s *Scanner) addChunks(chunks []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seenFiles == nil {
+		s.seenFiles = make(map[string]bool)
+	}
+	for _, chunk := range chunks {
+		if key := s.dedupeKey(chunk); !s.seenFiles[key] {
+			s.seenFiles[key] = true
+			s.pendingChunks = append(s.pendingChunks, chunk)
+		}
+	}
+}
+
+// markSeen records files that are about to be checked so chunk discovery
+// doesn't queue them again
+func (s *Scanner) markSeen(files []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seenFiles == nil {
+		s.seenFiles = make(map[string]bool)
+	}
+	for _, file := range files {
+		s.seenFiles[s.dedupeKey(file)] = true
+	}
+}
+
+// takeChunks returns and clears the queued chunk URLs
+func (s *Scanner) takeChunks() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chunks := s.pendingChunks
+	s.pendingChunks = nil
+	return chunks
+}
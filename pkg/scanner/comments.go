@@ -0,0 +1,152 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+)
+
+// inCommentTag is added to the tags of findings inside a JavaScript comment
+const inCommentTag = "in-comment"
+
+// lowerSeverity maps each severity to the next lower one for findings in
+// comments when DowngradeComments is set
+var lowerSeverity = map[string]string{
+	"":               SeverityLow,
+	SeverityLow:      SeverityLow,
+	SeverityMedium:   SeverityLow,
+	SeverityHigh:     SeverityMedium,
+	SeverityCritical: SeverityHigh,
+}
+
+// commentSpan is the [start, end) byte range of a comment in content
+type commentSpan struct {
+	start int
+	end   int
+}
+
+// findComments returns the // and /* */ comment regions of JavaScript
+// content in order. Strings, template literals, and regular expression
+// literals are skipped so "//" inside them, as in URLs, isn't taken for a
+// comment. It's a lexical scan, not a parser, and can be fooled by unusual
+// code.
+func findComments(content string) []commentSpan {
+	var spans []commentSpan
+	// prev is the last significant character of code, used to tell a
+	// division from the start of a regular expression literal
+	prev := byte(0)
+
+	for i := 0; i < len(content); i++ {
+		if end := commentEnd(content, i); end >= 0 {
+			spans = append(spans, commentSpan{i, end})
+			i = end - 1
+			continue
+		}
+
+		c := content[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			i = skipQuoted(content, i, c)
+			prev = c
+		case c == '/' && startsRegex(prev):
+			i = skipRegex(content, i)
+			prev = '/'
+		case c != ' ' && c != '\t' && c != '\r' && c != '\n':
+			prev = c
+		}
+	}
+
+	return spans
+}
+
+// commentEnd returns the offset just past the // or /* */ comment starting
+// at i in content, or -1 if none starts there. A // comment ends before its
+// newline, and an unclosed comment at the end of content.
+func commentEnd(content string, i int) int {
+	switch {
+	case strings.HasPrefix(content[i:], "//"):
+		if end := strings.IndexByte(content[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(content)
+	case strings.HasPrefix(content[i:], "/*"):
+		if end := strings.Index(content[i+2:], "*/"); end >= 0 {
+			return i + end + 4
+		}
+		return len(content)
+	}
+	return -1
+}
+
+// skipQuoted returns the index of the quote closing the string or template
+// literal opened at start. Plain strings also end at a newline.
+func skipQuoted(content string, start int, quote byte) int {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return len(content) - 1
+}
+
+// startsRegex checks if a slash after the code character prev begins a
+// regular expression literal rather than a division
+func startsRegex(prev byte) bool {
+	return prev == 0 || strings.IndexByte("(,=:[!&|?{};+-*%<>~^", prev) >= 0
+}
+
+// skipRegex returns the index of the slash closing the regular expression
+// literal opened at start, allowing for escapes and character classes
+func skipRegex(content string, start int) int {
+	inClass := false
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				return i
+			}
+		case '\n':
+			return i
+		}
+	}
+	return len(content) - 1
+}
+
+// inComment checks if the byte at offset lies in one of the sorted spans
+func inComment(spans []commentSpan, offset int) bool {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i].end > offset })
+	return i < len(spans) && spans[i].start <= offset
+}
+
+// tagComments tags findings whose match lies inside a comment of content
+// with in-comment, lowering their severity if DowngradeComments is set
+func (s *Scanner) tagComments(content string, findings []Finding) {
+	spans := findComments(content)
+	if len(spans) == 0 {
+		return
+	}
+
+	for i := range findings {
+		offset := findingOffset(content, findings[i])
+		if offset < 0 || !inComment(spans, offset) {
+			continue
+		}
+
+		findings[i].Tags = append(append([]string{}, findings[i].Tags...), inCommentTag)
+		if s.opts.DowngradeComments {
+			findings[i].Severity = lowerSeverity[findings[i].Severity]
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindComments(t *testing.T) {
+	content := "var u = \"http://x\"; // line\nvar r = /a\\/\\/b/; /* block */ x = 1 / 2; /* open"
+	var got []string
+	for _, span := range findComments(content) {
+		got = append(got, content[span.start:span.end])
+	}
+	want := []string{"// line", "/* block */", "/* open"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findComments() = %q, want %q", got, want)
+	}
+}
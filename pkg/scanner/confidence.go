@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Context confidence of a match preceded by a credential-like identifier,
+// either immediately or within the last few identifiers
+const (
+	contextConfidenceDirect = 1.0
+	contextConfidenceNearby = 0.5
+)
+
+// contextLookback is how many bytes before a secret are searched for the
+// identifier it's assigned to
+const contextLookback = 80
+
+// contextIdentifiers is how many of the closest preceding identifiers are
+// checked
+const contextIdentifiers = 3
+
+// contextKeywords are identifier fragments that mark a value as a
+// credential. "key" is handled separately so words like "keyboard" don't
+// count.
+var contextKeywords = []string{"secret", "token", "password", "passwd", "pwd", "auth", "credential"}
+
+// identifierRegex matches JavaScript identifiers and property names
+var identifierRegex = regexp.MustCompile(`[A-Za-z_$][\w$-]*`)
+
+// contextConfidence inspects the identifiers in the statement before
+// offset in content, returning the credential-like one closest to offset
+// and how strongly it suggests the value there is a credential (0 if none
+// does)
+func contextConfidence(content string, offset int) (string, float64) {
+	start := offset - contextLookback
+	if start < 0 {
+		start = 0
+	}
+	before := content[start:offset]
+	if i := strings.LastIndexAny(before, ";\n{"); i >= 0 {
+		before = before[i+1:]
+	}
+
+	identifiers := identifierRegex.FindAllString(before, -1)
+	for i := 0; i < len(identifiers) && i < contextIdentifiers; i++ {
+		identifier := identifiers[len(identifiers)-1-i]
+		if !isCredentialIdentifier(identifier) {
+			continue
+		}
+		if i == 0 {
+			return identifier, contextConfidenceDirect
+		}
+		return identifier, contextConfidenceNearby
+	}
+	return "", 0
+}
+
+// isCredentialIdentifier checks if an identifier names a credential, such
+// as apiKey, client_secret, or AUTH_TOKEN
+func isCredentialIdentifier(identifier string) bool {
+	lower := strings.ToLower(identifier)
+	if strings.HasSuffix(lower, "key") || strings.HasSuffix(lower, "keys") || strings.Contains(lower, "key_") || strings.Contains(lower, "key-") {
+		return true
+	}
+	for _, keyword := range contextKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// higherSeverity maps each severity to the next higher one for findings
+// assigned directly to a credential-like identifier. Findings without a
+// severity count as medium.
+var higherSeverity = map[string]string{
+	"":               SeverityHigh,
+	SeverityLow:      SeverityMedium,
+	SeverityMedium:   SeverityHigh,
+	SeverityHigh:     SeverityCritical,
+	SeverityCritical: SeverityCritical,
+}
+
+// weightByContext records the credential-like identifier the secret at
+// offset in content is assigned to, raising the finding's severity a level
+// when the identifier immediately precedes it
+func weightByContext(finding *Finding, content string, offset int) {
+	finding.ContextIdentifier, finding.ContextConfidence = contextConfidence(content, offset)
+	if finding.ContextConfidence >= contextConfidenceDirect {
+		finding.Severity = higherSeverity[finding.Severity]
+	}
+}
+
+// triageScore orders findings for review: the secret's entropy, weighted up
+// by the confidence that the surrounding code assigns a credential. Rules
+// without an entropy threshold don't record one, so it's computed here,
+// and findings without a secret are ordered by confidence alone.
+func triageScore(finding Finding) float64 {
+	entropy := finding.Entropy
+	if entropy == 0 {
+		entropy = calculateEntropy(finding.Secret)
+	}
+	if entropy == 0 {
+		return finding.ContextConfidence
+	}
+	return entropy * (1 + finding.ContextConfidence)
+}
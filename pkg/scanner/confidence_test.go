@@ -0,0 +1,30 @@
+package scanner
+
+import "testing"
+
+func TestWeightByContext(t *testing.T) {
+	content := `const apiKey = "0123456789abcdef0123456789abcdef01234567";`
+	finding := Finding{Secret: "0123456789abcdef0123456789abcdef01234567"}
+	weightByContext(&finding, content, len(`const apiKey = "`))
+	if finding.ContextIdentifier != "apiKey" || finding.ContextConfidence != contextConfidenceDirect {
+		t.Fatalf("context = %q %.1f, want apiKey %.1f", finding.ContextIdentifier, finding.ContextConfidence, contextConfidenceDirect)
+	}
+	if finding.Severity != SeverityHigh {
+		t.Errorf("severity = %q, want %q", finding.Severity, SeverityHigh)
+	}
+}
+
+func TestTriageScoreWithoutRecordedEntropy(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef01234567"
+	plain := Finding{Secret: secret}
+	assigned := Finding{Secret: secret, ContextConfidence: contextConfidenceDirect}
+	if triageScore(plain) == 0 {
+		t.Error("a finding without recorded entropy scored 0")
+	}
+	if triageScore(assigned) <= triageScore(plain) {
+		t.Errorf("assigned score %.2f isn't above plain %.2f", triageScore(assigned), triageScore(plain))
+	}
+	if score := triageScore(Finding{ContextConfidence: contextConfidenceNearby}); score != contextConfidenceNearby {
+		t.Errorf("score without a secret = %.2f, want %.2f", score, contextConfidenceNearby)
+	}
+}
@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+)
+
+var (
+	// configFetchRegex matches requests for a literal URL made with fetch,
+	// axios, jQuery, or XMLHttpRequest, such as fetch("/config.json")
+	configFetchRegex = regexp.MustCompile("(?:\\bfetch|\\baxios(?:\\s*\\.\\s*get)?|\\$\\s*\\.\\s*(?:getJSON|get|ajax)|\\.open\\s*\\(\\s*[\"']GET[\"']\\s*,)\\s*\\(?\\s*[\"'`]([^\"'`\\s]+)[\"'`]")
+	// configFileRegex matches the file names of runtime config files, such
+	// as config.json, env.js, app-settings.yaml, or runtime-config.json
+	configFileRegex = regexp.MustCompile(`(?i)^(?:[\w-]*[._-])?(?:config|configuration|settings|appsettings|env|environment)(?:[._-][\w.-]*)?\.(?:json|ya?ml|js)$`)
+)
+
+// extractConfigURLs returns the URLs of the config files the file at fileURL
+// fetches at runtime, such as fetch("/config.json") or fetch("env.js").
+// Relative URLs resolve against the page, approximated by target when
+// known, as requests do.
+func (s *Scanner) extractConfigURLs(fileURL string, target string, content string) []string {
+	base, err := url.Parse(fileURL)
+	if err != nil {
+		return nil
+	}
+	if target != "" {
+		if u, err := url.Parse(target); err == nil {
+			base = u
+		}
+	}
+
+	var configs []string
+	seen := make(map[string]bool)
+	for _, match := range configFetchRegex.FindAllStringSubmatch(content, -1) {
+		normalized, err := normalizeJSURL(base, match[1], s.opts.StripQuery)
+		if err != nil || normalized == fileURL || !s.isScannableFile(normalized) || seen[normalized] {
+			continue
+		}
+		u, err := url.Parse(normalized)
+		if err != nil || !configFileRegex.MatchString(path.Base(u.Path)) {
+			continue
+		}
+		seen[normalized] = true
+		configs = append(configs, normalized)
+	}
+	return configs
+}
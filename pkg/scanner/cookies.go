@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in Netscape cookie files
+const httpOnlyPrefix = "#HttpOnly_"
+
+// ParseCookieFile reads cookies from a Netscape/cookies.txt file. Cookies
+// that apply to subdomains keep a leading "." on their Domain.
+func ParseCookieFile(path string) ([]*http.Cookie, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cookie file: %v", err)
+	}
+	defer file.Close()
+
+	var cookies []*http.Cookie
+	lineNum := 0
+	fileScanner := bufio.NewScanner(file)
+	for fileScanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(fileScanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			httpOnly = true
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// domain, include subdomains, path, secure, expiry, name, value
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid cookie on line %d: expected 7 tab-separated fields, got %d", lineNum, len(fields))
+		}
+
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+
+		cookie := &http.Cookie{
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+			Name:     fields[5],
+			Value:    fields[6],
+		}
+
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie expiry on line %d: %v", lineNum, err)
+		}
+		if expiry > 0 {
+			cookie.Expires = time.Unix(expiry, 0)
+		}
+
+		cookies = append(cookies, cookie)
+	}
+	if err := fileScanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cookie file: %v", err)
+	}
+
+	return cookies, nil
+}
+
+// playwrightCookies converts domain-scoped cookies into Playwright cookies
+func playwrightCookies(cookies []*http.Cookie) []playwright.OptionalCookie {
+	var converted []playwright.OptionalCookie
+	for _, cookie := range cookies {
+		path := cookie.Path
+		if path == "" {
+			path = "/"
+		}
+
+		optional := playwright.OptionalCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   playwright.String(cookie.Domain),
+			Path:     playwright.String(path),
+			Secure:   playwright.Bool(cookie.Secure),
+			HttpOnly: playwright.Bool(cookie.HttpOnly),
+		}
+		if !cookie.Expires.IsZero() {
+			optional.Expires = playwright.Float(float64(cookie.Expires.Unix()))
+		}
+		converted = append(converted, optional)
+	}
+	return converted
+}
+
+// cookieApplies checks if a domain-scoped cookie should be sent to u
+func cookieApplies(cookie *http.Cookie, u *url.URL) bool {
+	if !cookie.Expires.IsZero() && cookie.Expires.Before(time.Now()) {
+		return false
+	}
+	if cookie.Secure && u.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	domain := strings.ToLower(cookie.Domain)
+	if strings.HasPrefix(domain, ".") {
+		if host != domain[1:] && !strings.HasSuffix(host, domain) {
+			return false
+		}
+	} else if host != domain {
+		return false
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	if cookie.Path == "" || cookie.Path == "/" || path == cookie.Path {
+		return true
+	}
+	return strings.HasPrefix(path, cookie.Path) &&
+		(strings.HasSuffix(cookie.Path, "/") || path[len(cookie.Path)] == '/')
+}
@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+)
+
+// crossFileTag is added to the tags of CorpusScan findings whose match
+// spans more than one file
+const crossFileTag = "cross-file"
+
+// corpusPart is one file's content within a target's corpus
+type corpusPart struct {
+	File    string
+	Content string
+}
+
+// corpusRegion is the [start, end) byte range of a file in a corpus
+type corpusRegion struct {
+	file  string
+	start int
+	end   int
+}
+
+// addCorpusContent keeps content from file for target's corpus when
+// CorpusScan is set
+func (s *Scanner) addCorpusContent(file string, target string, content string) {
+	if !s.opts.CorpusScan || content == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.corpora == nil {
+		s.corpora = make(map[string][]corpusPart)
+	}
+	if _, ok := s.corpora[target]; !ok {
+		s.corpusTargets = append(s.corpusTargets, target)
+	}
+	s.corpora[target] = append(s.corpora[target], corpusPart{File: file, Content: content})
+}
+
+// checkCorpora scans the corpus of each target whose files have been
+// checked, reporting the matches the per-file scans missed
+func (s *Scanner) checkCorpora() {
+	s.mu.Lock()
+	targets := s.corpusTargets
+	corpora := s.corpora
+	s.corpusTargets = nil
+	s.corpora = nil
+	s.mu.Unlock()
+
+	for _, target := range targets {
+		if s.limitReached() {
+			return
+		}
+		if parts := corpora[target]; len(parts) > 1 {
+			s.checkCorpus(target, parts)
+		}
+	}
+}
+
+// checkCorpus runs the rules over a target's files concatenated back to
+// back, so that a secret split across two files reads as one, attributing
+// each new match to the file, or files, it came from
+func (s *Scanner) checkCorpus(target string, parts []corpusPart) {
+	var corpus strings.Builder
+	regions := make([]corpusRegion, 0, len(parts))
+	for _, part := range parts {
+		start := corpus.Len()
+		corpus.WriteString(part.Content)
+		regions = append(regions, corpusRegion{file: part.File, start: start, end: corpus.Len()})
+	}
+	content := corpus.String()
+	s.debugf("Scanning the %d-byte corpus of %d files for %s", len(content), len(parts), target)
+
+	// Matches the per-file scans found are already reported
+	reported := make(map[string]bool)
+	for _, finding := range s.GetFindings() {
+		if finding.Target == target {
+			reported[finding.RuleID+":"+finding.Secret] = true
+		}
+	}
+
+	var findings []Finding
+	for _, finding := range s.findSecrets(target+" [corpus]", content) {
+		if reported[finding.RuleID+":"+finding.Secret] {
+			continue
+		}
+		pos := findingOffset(content, finding)
+		if pos < 0 {
+			continue
+		}
+		files := corpusFiles(regions, pos, pos+len(finding.Line))
+		if len(files) == 0 {
+			continue
+		}
+		reported[finding.RuleID+":"+finding.Secret] = true
+
+		first := regions[corpusRegionAt(regions, pos)]
+		finding.File = files[0]
+		finding.LineNumber = strings.Count(content[first.start:pos], "\n") + 1
+		if len(files) > 1 {
+			finding.Tags = append(append([]string{}, finding.Tags...), crossFileTag)
+			finding.CorpusFiles = files
+		}
+		findings = append(findings, finding)
+	}
+	s.tagComments(content, findings)
+	for i := range findings {
+		findings[i].Target = target
+	}
+	s.addFindings(findings)
+}
+
+// corpusRegionAt returns the index of the region containing offset
+func corpusRegionAt(regions []corpusRegion, offset int) int {
+	i := sort.Search(len(regions), func(i int) bool { return regions[i].end > offset })
+	if i == len(regions) {
+		i--
+	}
+	return i
+}
+
+// corpusFiles returns the files whose regions overlap [start, end)
+func corpusFiles(regions []corpusRegion, start int, end int) []string {
+	var files []string
+	for i := corpusRegionAt(regions, start); i < len(regions) && regions[i].start < end; i++ {
+		if regions[i].end > start {
+			files = append(files, regions[i].file)
+		}
+	}
+	return files
+}
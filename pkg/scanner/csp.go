@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// cspTag is added to the tags of Content-Security-Policy findings
+const cspTag = "csp"
+
+// cspMetaScript returns the policies of the page's <meta http-equiv>
+// Content-Security-Policy tags
+const cspMetaScript = `() => Array.from(document.querySelectorAll('meta[http-equiv]'))
+	.filter(meta => meta.httpEquiv.toLowerCase() === 'content-security-policy')
+	.map(meta => meta.content)`
+
+// The pseudo rules of permissive Content-Security-Policy findings
+var (
+	cspUnsafeInlineRule = config.Rule{
+		ID:          "csp-unsafe-inline",
+		Description: "Content-Security-Policy allows inline scripts with 'unsafe-inline'",
+		Tags:        []string{cspTag},
+	}
+	cspWildcardRule = config.Rule{
+		ID:          "csp-wildcard-source",
+		Description: "Content-Security-Policy allows any source with a wildcard",
+		Tags:        []string{cspTag},
+	}
+	cspMissingScriptSrcRule = config.Rule{
+		ID:          "csp-missing-script-src",
+		Description: "Content-Security-Policy doesn't restrict scripts with script-src or default-src",
+		Tags:        []string{cspTag},
+	}
+)
+
+// cspDirective is one directive of a policy, such as
+// "script-src 'self' https://cdn.example.com"
+type cspDirective struct {
+	Name    string
+	Sources []string
+	Text    string
+}
+
+// parseCSP splits a policy into its directives. Only the first occurrence
+// of a directive counts, as in browsers.
+func parseCSP(policy string) []cspDirective {
+	var directives []cspDirective
+	seen := make(map[string]bool)
+	for _, text := range strings.Split(policy, ";") {
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		directives = append(directives, cspDirective{Name: name, Sources: fields[1:], Text: strings.Join(fields, " ")})
+	}
+	return directives
+}
+
+// allowsUnsafeInline checks if a script directive allows inline scripts.
+// Browsers ignore 'unsafe-inline' when a nonce or hash is also listed.
+func (d cspDirective) allowsUnsafeInline() bool {
+	unsafe := false
+	for _, source := range d.Sources {
+		source = strings.ToLower(source)
+		if strings.HasPrefix(source, "'nonce-") || strings.HasPrefix(source, "'sha") {
+			return false
+		}
+		if source == "'unsafe-inline'" {
+			unsafe = true
+		}
+	}
+	return unsafe
+}
+
+// hasWildcard checks if a directive allows any host, with * or a bare
+// http: or https: scheme
+func (d cspDirective) hasWildcard() bool {
+	for _, source := range d.Sources {
+		switch strings.ToLower(source) {
+		case "*", "http:", "https:":
+			return true
+		}
+	}
+	return false
+}
+
+// cspFindings reports the permissive directives of the policies a page
+// was served with, or a missing script-src when it has none
+func (s *Scanner) cspFindings(pageURL string, policies []string) []Finding {
+	var findings []Finding
+	add := func(rule config.Rule, directive string, policy string) {
+		findings = append(findings, Finding{
+			Description: rule.Description,
+			File:        pageURL,
+			RuleID:      rule.ID,
+			Tags:        rule.Tags,
+			Secret:      directive,
+			Context:     policy,
+			Line:        directive,
+			Severity:    SeverityLow,
+		})
+	}
+
+	restricted := false
+	for _, policy := range policies {
+		var script *cspDirective
+		directives := parseCSP(policy)
+		for i, directive := range directives {
+			if directive.hasWildcard() {
+				add(cspWildcardRule, directive.Text, policy)
+			}
+			if directive.Name == "script-src" || (directive.Name == "default-src" && script == nil) {
+				script = &directives[i]
+			}
+		}
+		if script == nil {
+			continue
+		}
+		restricted = true
+		if script.allowsUnsafeInline() {
+			add(cspUnsafeInlineRule, script.Text, policy)
+		}
+	}
+	// Every policy is enforced, so one restricting scripts is enough
+	if !restricted {
+		policy := strings.Join(policies, ", ")
+		if policy == "" {
+			policy = "(no Content-Security-Policy)"
+		}
+		add(cspMissingScriptSrcRule, policy, policy)
+	}
+	return findings
+}
+
+// splitCSPHeader splits Content-Security-Policy header values into their
+// policies, which a single header can hold separated by commas
+func splitCSPHeader(values []string) []string {
+	var policies []string
+	for _, value := range values {
+		policies = append(policies, strings.Split(value, ",")...)
+	}
+	return policies
+}
+
+// addCSP queues the Content-Security-Policy findings of a page, from its
+// response headers and meta tags, to be reported with its files
+func (s *Scanner) addCSP(pageURL string, policies []string) {
+	var kept []string
+	for _, policy := range policies {
+		if strings.TrimSpace(policy) != "" {
+			kept = append(kept, strings.TrimSpace(policy))
+		}
+	}
+	findings := s.cspFindings(pageURL, kept)
+	s.debugf("Found %d Content-Security-Policy issues on %s", len(findings), pageURL)
+	s.addPageFindings(findings)
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minBase64Length is the shortest token considered for base64 decoding
+const minBase64Length = 20
+
+// base64TokenRegex matches standard and URL-safe base64-looking tokens
+var base64TokenRegex = regexp.MustCompile(`[A-Za-z0-9+/_-]{20,}={0,2}`)
+
+// scanBase64 finds base64-encoded tokens in content, decodes them, and runs
+// the detection rules over the decoded text. Findings are tagged "decoded"
+// and record the encoded token they came from.
+func (s *Scanner) scanBase64(file string, content string, reportedMatches map[string]bool) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+
+	for _, token := range base64TokenRegex.FindAllString(content, -1) {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+
+		decoded, ok := decodeBase64Token(token)
+		if !ok {
+			continue
+		}
+
+		for _, finding := range s.scanContent(file, decoded, reportedMatches) {
+			finding.Tags = append(append([]string{}, finding.Tags...), "decoded")
+			finding.DecodedFrom = token
+			finding.CodeSnippet = s.codeSnippet(content, token)
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// decodeBase64Token decodes token as base64 and returns the result if it is
+// printable UTF-8 text
+func decodeBase64Token(token string) (string, bool) {
+	if len(token) < minBase64Length {
+		return "", false
+	}
+
+	// Padded tokens must be a multiple of four characters long
+	encodings := []*base64.Encoding{base64.RawStdEncoding, base64.RawURLEncoding}
+	if strings.HasSuffix(token, "=") {
+		if len(token)%4 != 0 {
+			return "", false
+		}
+		encodings = []*base64.Encoding{base64.StdEncoding, base64.URLEncoding}
+	}
+
+	for _, encoding := range encodings {
+		data, err := encoding.DecodeString(token)
+		if err != nil {
+			continue
+		}
+		if isPrintableText(data) {
+			return string(data), true
+		}
+	}
+	return "", false
+}
+
+// isPrintableText checks if data is valid UTF-8 made up of printable
+// characters and whitespace
+func isPrintableText(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
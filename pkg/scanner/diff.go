@@ -0,0 +1,137 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DiffResult groups findings by how they changed between two scans
+type DiffResult struct {
+	Added     []Finding `json:"added"`
+	Removed   []Finding `json:"removed"`
+	Unchanged []Finding `json:"unchanged"`
+}
+
+// LoadFindings reads the findings from a JSON or JSON Lines report written
+// by jsweb. Findings saved without a fingerprint get one computed.
+func LoadFindings(path string) ([]Finding, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open findings file: %v", err)
+	}
+	defer file.Close()
+
+	// A JSON report is a single document, so only the first is read and a
+	// trailing --summary is fine. Otherwise each document is a finding,
+	// after the metadata header record of a JSON Lines report.
+	var findings []Finding
+	decoder := json.NewDecoder(file)
+	for {
+		var doc struct {
+			Findings *[]Finding      `json:"findings"`
+			Metadata *ReportMetadata `json:"metadata"`
+			Finding
+		}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse findings file %s: %v", path, err)
+		}
+		if doc.Findings != nil && findings == nil {
+			findings = *doc.Findings
+			break
+		}
+		if doc.Metadata != nil && doc.RuleID == "" {
+			continue
+		}
+		findings = append(findings, doc.Finding)
+	}
+
+	for i := range findings {
+		if findings[i].Fingerprint == "" {
+			findings[i].Fingerprint = Fingerprint(findings[i].RuleID, findings[i].File, findings[i].Secret)
+		}
+	}
+	return findings, nil
+}
+
+// DiffFindings compares two sets of findings by fingerprint
+func DiffFindings(oldFindings []Finding, newFindings []Finding) DiffResult {
+	oldSet := make(map[string]bool)
+	for _, finding := range oldFindings {
+		oldSet[finding.Fingerprint] = true
+	}
+	newSet := make(map[string]bool)
+	for _, finding := range newFindings {
+		newSet[finding.Fingerprint] = true
+	}
+
+	result := DiffResult{
+		Added:     []Finding{},
+		Removed:   []Finding{},
+		Unchanged: []Finding{},
+	}
+	for _, finding := range newFindings {
+		if oldSet[finding.Fingerprint] {
+			result.Unchanged = append(result.Unchanged, finding)
+		} else {
+			result.Added = append(result.Added, finding)
+		}
+	}
+	for _, finding := range oldFindings {
+		if !newSet[finding.Fingerprint] {
+			result.Removed = append(result.Removed, finding)
+		}
+	}
+	return result
+}
+
+// WriteDiff writes a diff to w in the given format. The gitlab format
+// only reports the added findings.
+func WriteDiff(w io.Writer, format string, diff DiffResult, info ReportInfo) error {
+	switch format {
+	case "", "json":
+		jsonData, err := marshalJSON(diff, info.Compact)
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff: %v", err)
+		}
+		if _, err := fmt.Fprintln(w, string(jsonData)); err != nil {
+			return fmt.Errorf("failed to write diff: %v", err)
+		}
+		return nil
+	case "jsonl":
+		return writeJSONLDiff(w, diff)
+	case "html":
+		return writeHTMLDiff(w, diff, info)
+	case "text":
+		return writeTextDiff(w, diff, info)
+	case GitLabFormat:
+		return writeGitLab(w, diff.Added, info)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// writeJSONLDiff writes each finding of a diff as a JSON line with a
+// "change" field of added, removed, or unchanged
+func writeJSONLDiff(w io.Writer, diff DiffResult) error {
+	for _, group := range []struct {
+		change   string
+		findings []Finding
+	}{{"added", diff.Added}, {"removed", diff.Removed}, {"unchanged", diff.Unchanged}} {
+		for _, finding := range group.findings {
+			line := struct {
+				Change string `json:"change"`
+				Finding
+			}{group.change, finding}
+			if err := writeJSONLine(w, line); err != nil {
+				return fmt.Errorf("failed to write diff: %v", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nautical/jsweb/pkg/utils"
+)
+
+// IgnoreFileName is the file of patterns read from the root of a directory
+// scanned with ScanDir
+const IgnoreFileName = ".jswebignore"
+
+// DefaultExtensions are the file extensions ScanDir scans when
+// Options.Extensions is empty
+var DefaultExtensions = []string{".js", ".mjs", ".cjs"}
+
+// ScanDir scans the files under root with one of Options.Extensions,
+// following symlinks but visiting each directory once. Files matching a
+// pattern in root's .jswebignore are skipped, as are matches of IgnoreFiles.
+// Findings name the file's path, joined to root, as their file and root as
+// their target. The walk stops with ctx's error once ctx is done.
+func (s *Scanner) ScanDir(ctx context.Context, root string) error {
+	if s.optionsErr != nil {
+		return s.optionsErr
+	}
+
+	start := time.Now()
+	err := s.scanDir(ctx, root)
+	s.recordRun(time.Since(start), err)
+	return err
+}
+
+// scanDir performs the scan for ScanDir
+func (s *Scanner) scanDir(ctx context.Context, root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %v", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s isn't a directory", root)
+	}
+
+	var ignore []string
+	if _, err := os.Stat(filepath.Join(root, IgnoreFileName)); err == nil {
+		ignore, err = utils.ReadPatternFile(filepath.Join(root, IgnoreFileName))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", IgnoreFileName, err)
+		}
+	}
+
+	walker := &dirWalker{ctx: ctx, scanner: s, root: root, ignore: ignore, visited: make(map[string]bool)}
+	return walker.walk(root)
+}
+
+// dirWalker walks a directory tree for ScanDir
+type dirWalker struct {
+	ctx     context.Context
+	scanner *Scanner
+	root    string
+	ignore  []string
+	// visited holds the resolved path of each directory walked, so symlink
+	// loops and directories linked more than once are only walked once
+	visited map[string]bool
+}
+
+// walk scans the files in dir and walks its subdirectories
+func (w *dirWalker) walk(dir string) error {
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %v", dir, err)
+	}
+	if w.visited[resolved] {
+		w.scanner.debugf("Skipping %s, already walked as %s", dir, resolved)
+		return nil
+	}
+	w.visited[resolved] = true
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %v", err)
+	}
+	for _, entry := range entries {
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		if w.scanner.limitReached() {
+			return nil
+		}
+
+		name := filepath.Join(dir, entry.Name())
+		// Stat follows symlinks, so linked files and directories are
+		// handled like their targets
+		info, err := os.Stat(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Skipping %s: %v\n", name, err)
+			continue
+		}
+		if w.ignored(name, info.IsDir()) {
+			w.scanner.debugf("Skipping %s, matched %s", name, IgnoreFileName)
+			continue
+		}
+
+		if info.IsDir() {
+			if err := w.walk(name); err != nil {
+				return err
+			}
+		} else if info.Mode().IsRegular() && w.scanner.hasExtension(name) {
+			w.scanner.checkLocalFile(name, w.root, info.Size())
+		}
+	}
+	return nil
+}
+
+// ignored checks if a path matches a .jswebignore pattern. Patterns are
+// globs matched against the slash-separated path relative to the root and
+// against the file name, where ** matches any number of directories; a
+// trailing slash only matches directories.
+func (w *dirWalker) ignored(name string, isDir bool) bool {
+	rel, err := filepath.Rel(w.root, name)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range w.ignore {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		pattern = strings.TrimPrefix(pattern, "/")
+		if matchGlob(pattern, rel) {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob checks if the slash-separated name matches pattern, a glob
+// whose ** segments match zero or more path segments, so vendor/** matches
+// vendor and everything under it and **/*.min.js matches at any depth
+func matchGlob(pattern string, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches the segments of a name against those of a glob
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// hasExtension checks if name has one of Options.Extensions, or of
+// DefaultExtensions if none are set
+func (s *Scanner) hasExtension(name string) bool {
+	extensions := s.opts.Extensions
+	if len(extensions) == 0 {
+		extensions = DefaultExtensions
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, want := range extensions {
+		if ext == "."+strings.TrimPrefix(strings.ToLower(want), ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLocalFile reads a file found by ScanDir and scans it like a fetched
+// one, recording it in the scan report
+func (s *Scanner) checkLocalFile(name string, root string, size int64) {
+	report := FileReport{URL: name, Status: FileScanned}
+	defer func() { s.recordFile(report) }()
+	skip := func(reason string) {
+		report.Status = FileSkipped
+		report.Reason = reason
+	}
+
+	if s.isIgnored(name) {
+		s.debugf("Skipping ignored file %s", name)
+		skip("matched ignore pattern")
+		return
+	}
+	if s.isExcluded(name) {
+		s.debugf("Skipping file %s not matching an include pattern", name)
+		skip("matched no include pattern")
+		return
+	}
+	if maxSize := s.opts.MaxFileSize; maxSize > 0 && size > maxSize {
+		fmt.Fprintf(os.Stderr, "Warning: Skipping %s: size %d bytes exceeds limit of %d bytes\n", name, size, maxSize)
+		skip(fmt.Sprintf("size %d bytes exceeds limit of %d bytes", size, maxSize))
+		return
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		report.Status = FileError
+		report.Reason = err.Error()
+		fmt.Fprintf(os.Stderr, "Error checking file %s: %v\n", name, err)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	report.BytesRead = int64(len(content))
+	if err != nil {
+		report.Status = FileError
+		report.Reason = err.Error()
+		fmt.Fprintf(os.Stderr, "Error checking file %s: %v\n", name, err)
+		return
+	}
+
+	if original := s.duplicateOf(name, content); original != "" {
+		s.debugf("Skipping %s: same content as %s", name, original)
+		skip("same content as " + original)
+		return
+	}
+
+	report.BytesScanned, report.MatchDuration = s.checkContent(name, root, "", content)
+}
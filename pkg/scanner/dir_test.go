@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"vendor/**", "vendor", true},
+		{"vendor/**", "vendor/lib/a.js", true},
+		{"vendor/**", "src/vendor/a.js", false},
+		{"**/*.min.js", "a.min.js", true},
+		{"**/*.min.js", "dist/js/a.min.js", true},
+		{"**/*.min.js", "dist/js/a.js", false},
+		{"src/**/test/*.js", "src/test/a.js", true},
+		{"src/**/test/*.js", "src/a/b/test/a.js", true},
+		{"src/*.js", "src/a/b.js", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanDirIgnoresPatternsAndSymlinkLoops(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app.js":              "var a = 1;",
+		"lib/util.js":         "var b = 2;",
+		"lib/util.min.js":     "var c = 3;",
+		"vendor/dep/index.js": "var d = 4;",
+		IgnoreFileName:        "# dependencies\nvendor/**\n**/*.min.js\nbuild/\n",
+		"build/out.js":        "var e = 5;",
+		"lib/readme.txt":      "not javascript",
+	}
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("..", filepath.Join(root, "lib", "loop")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	var mu sync.Mutex
+	var scanned []string
+	s := New(&config.Config{}, Options{OnFileScanned: func(report FileReport) {
+		mu.Lock()
+		defer mu.Unlock()
+		rel, err := filepath.Rel(root, report.URL)
+		if err != nil {
+			t.Errorf("scanned file %s outside the root", report.URL)
+			return
+		}
+		scanned = append(scanned, filepath.ToSlash(rel))
+	}})
+	if err := s.ScanDir(context.Background(), root); err != nil {
+		t.Fatalf("ScanDir: %v", err)
+	}
+
+	sort.Strings(scanned)
+	want := []string{"app.js", "lib/util.js"}
+	if len(scanned) != len(want) {
+		t.Fatalf("scanned %v, want %v", scanned, want)
+	}
+	for i := range want {
+		if scanned[i] != want[i] {
+			t.Fatalf("scanned %v, want %v", scanned, want)
+		}
+	}
+}
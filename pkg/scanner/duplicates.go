@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// duplicateOf returns the URL of a file already scanned with the same
+// content as fileURL, recording fileURL as its alias, or "" if the content
+// is new. It always returns "" unless DedupeContent is set.
+func (s *Scanner) duplicateOf(fileURL string, content []byte) string {
+	if !s.opts.DedupeContent {
+		return ""
+	}
+	// Aliases are matched against findings' files, which are canonical
+	if s.opts.CanonicalizeHost {
+		fileURL = CanonicalURL(fileURL)
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.contentFiles == nil {
+		s.contentFiles = make(map[string]string)
+		s.contentAliases = make(map[string][]string)
+	}
+	original, ok := s.contentFiles[hash]
+	if !ok {
+		s.contentFiles[hash] = fileURL
+		return ""
+	}
+
+	s.contentAliases[original] = append(s.contentAliases[original], fileURL)
+	for i := range s.findings {
+		if s.findings[i].File == original {
+			s.findings[i].Aliases = append(append([]string{}, s.findings[i].Aliases...), fileURL)
+		}
+	}
+	return original
+}
@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// emptyPageTextLength is the visible text length below which a page
+// without scripts looks like discovery failed rather than a page that
+// genuinely has no JavaScript
+const emptyPageTextLength = 200
+
+// retryNavigationDelay is how long RetryNavigation waits on an empty page
+// without a meta refresh before collecting its scripts again
+const retryNavigationDelay = 5 * time.Second
+
+// pageStateScript returns the number of <script> elements of the page, the
+// length of its visible text, and the resolved target of its meta refresh
+// (empty if none)
+const pageStateScript = `() => {
+	const refresh = document.querySelector('meta[http-equiv="refresh" i]');
+	const match = refresh && /url\s*=\s*['"]?([^'"]+)/i.exec(refresh.getAttribute('content') || '');
+	let target = '';
+	try {
+		target = match ? new URL(match[1].trim(), document.baseURI).href : '';
+	} catch (e) {}
+	const text = document.body ? document.body.innerText.trim() : '';
+	return [document.scripts.length, text.length, target];
+}`
+
+// pageState is the result of pageStateScript
+type pageState struct {
+	Scripts    int
+	TextLength int
+	Refresh    string
+}
+
+// looksEmpty checks if the page has neither scripts nor much content, as
+// with bot challenges and redirects the browser didn't follow
+func (p pageState) looksEmpty() bool {
+	return p.Scripts == 0 && p.TextLength < emptyPageTextLength
+}
+
+// evaluatePageState runs pageStateScript on page
+func evaluatePageState(page playwright.Page) (pageState, error) {
+	result, err := page.Evaluate(pageStateScript)
+	if err != nil {
+		return pageState{}, err
+	}
+	values, _ := result.([]interface{})
+	if len(values) != 3 {
+		return pageState{}, fmt.Errorf("unexpected page state %v", result)
+	}
+	scripts, _ := toFloat(values[0])
+	textLength, _ := toFloat(values[1])
+	refresh, _ := values[2].(string)
+	return pageState{Scripts: int(scripts), TextLength: int(textLength), Refresh: refresh}, nil
+}
+
+// retryEmptyPage checks if a page where no JavaScript files were found
+// looks empty, and warns that discovery likely failed if so. With
+// RetryNavigation, it first follows the page's meta refresh, or waits
+// longer for a JavaScript redirect or challenge, and returns the files
+// found on a second attempt.
+func (s *Scanner) retryEmptyPage(page playwright.Page, pageURL string) ([]string, error) {
+	state, err := evaluatePageState(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect page: %v", err)
+	}
+	if !state.looksEmpty() {
+		return nil, nil
+	}
+
+	if s.opts.RetryNavigation {
+		if state.Refresh != "" {
+			s.debugf("Following the meta refresh of %s to %s", pageURL, state.Refresh)
+			if _, err := page.Goto(state.Refresh, s.gotoOptions()); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Following the meta refresh of %s failed: %v\n", pageURL, err)
+			}
+		} else {
+			s.debugf("Waiting %s more for %s, which looks empty", retryNavigationDelay, pageURL)
+			page.WaitForTimeout(float64(retryNavigationDelay.Milliseconds()))
+			page.WaitForLoadState()
+		}
+
+		jsFiles, err := s.FindJSFiles(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find JavaScript files: %v", err)
+		}
+		if len(jsFiles) > 0 {
+			return jsFiles, nil
+		}
+		if state, err = evaluatePageState(page); err != nil {
+			return nil, fmt.Errorf("failed to inspect page: %v", err)
+		}
+		if !state.looksEmpty() {
+			return nil, nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s still looks empty after retrying (no scripts, %d characters of text); JavaScript discovery likely failed, e.g. on a bot challenge or login wall\n", pageURL, state.TextLength)
+		return nil, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: %s looks empty (no scripts, %d characters of text); JavaScript discovery likely failed, e.g. on a bot challenge or an unfollowed redirect. Try --retry-navigation or --trace\n", pageURL, state.TextLength)
+	return nil, nil
+}
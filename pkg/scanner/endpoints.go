@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/utils"
+)
+
+// Endpoint kinds
+const (
+	EndpointURL     = "url"
+	EndpointPath    = "path"
+	EndpointGraphQL = "graphql"
+)
+
+// Endpoint is an API endpoint or GraphQL operation referenced by a file
+type Endpoint struct {
+	Kind  string   `json:"kind"`
+	Value string   `json:"value"`
+	Files []string `json:"files"`
+}
+
+var (
+	// endpointURLRegex matches absolute URLs in string literals
+	endpointURLRegex = regexp.MustCompile("[\"'`](https?://[^\\s\"'`<>\\\\]+)[\"'`]")
+	// endpointPathRegex matches root-relative paths in string literals
+	endpointPathRegex = regexp.MustCompile("[\"'`](/[A-Za-z0-9_.~%-]+(?:/[A-Za-z0-9_.~%{}:$-]*)*(?:\\?[^\\s\"'`<>]*)?)[\"'`]")
+	// graphQLOperationRegex matches named GraphQL operations
+	graphQLOperationRegex = regexp.MustCompile(`\b(query|mutation|subscription)\s+([A-Za-z_][A-Za-z0-9_]*)\s*[({]`)
+)
+
+// staticAssetExtensions are file extensions of URLs that aren't API endpoints
+var staticAssetExtensions = []string{
+	".js", ".mjs", ".css", ".map", ".png", ".jpg", ".jpeg", ".gif", ".svg",
+	".webp", ".ico", ".woff", ".woff2", ".ttf", ".eot", ".mp4", ".webm",
+}
+
+// extractEndpoints returns the URLs, root-relative paths, and GraphQL
+// operations referenced in content
+func extractEndpoints(content string) []Endpoint {
+	var endpoints []Endpoint
+	seen := make(map[string]bool)
+	add := func(kind string, value string) {
+		key := kind + "\x00" + value
+		if !seen[key] {
+			seen[key] = true
+			endpoints = append(endpoints, Endpoint{Kind: kind, Value: value})
+		}
+	}
+
+	for _, match := range endpointURLRegex.FindAllStringSubmatch(content, -1) {
+		if u, err := url.Parse(match[1]); err == nil && u.Host != "" && !isStaticAsset(u.Path) {
+			add(EndpointURL, match[1])
+		}
+	}
+	for _, match := range endpointPathRegex.FindAllStringSubmatch(content, -1) {
+		if !isStaticAsset(strings.SplitN(match[1], "?", 2)[0]) {
+			add(EndpointPath, match[1])
+		}
+	}
+	for _, match := range graphQLOperationRegex.FindAllStringSubmatch(content, -1) {
+		add(EndpointGraphQL, match[1]+" "+match[2])
+	}
+
+	return endpoints
+}
+
+// isStaticAsset checks if a URL path points to a static asset
+func isStaticAsset(urlPath string) bool {
+	ext := strings.ToLower(path.Ext(urlPath))
+	for _, assetExt := range staticAssetExtensions {
+		if ext == assetExt {
+			return true
+		}
+	}
+	return false
+}
+
+// addEndpoints records the endpoints referenced by file
+func (s *Scanner) addEndpoints(file string, endpoints []Endpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.endpoints == nil {
+		s.endpoints = make(map[string]*Endpoint)
+	}
+	for _, endpoint := range endpoints {
+		key := endpoint.Kind + "\x00" + endpoint.Value
+		existing, ok := s.endpoints[key]
+		if !ok {
+			existing = &Endpoint{Kind: endpoint.Kind, Value: endpoint.Value}
+			s.endpoints[key] = existing
+		}
+		// Files scanned in windows can repeat an endpoint in the overlap
+		if !utils.Contains(existing.Files, file) {
+			existing.Files = append(existing.Files, file)
+		}
+	}
+}
+
+// addOrigin records the origin of a scanned page for same-origin filtering
+func (s *Scanner) addOrigin(pageURL string) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.origins == nil {
+		s.origins = make(map[string]bool)
+	}
+	s.origins[strings.ToLower(u.Host)] = true
+}
+
+// GetEndpoints returns the deduplicated endpoints found so far, sorted by
+// kind and value. With SameOriginEndpoints set, absolute URLs on other
+// hosts than the scanned pages are left out.
+func (s *Scanner) GetEndpoints() []Endpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var endpoints []Endpoint
+	for _, endpoint := range s.endpoints {
+		if s.opts.SameOriginEndpoints && endpoint.Kind == EndpointURL {
+			u, err := url.Parse(endpoint.Value)
+			if err != nil || !s.origins[strings.ToLower(u.Host)] {
+				continue
+			}
+		}
+
+		files := append([]string{}, endpoint.Files...)
+		sort.Strings(files)
+		endpoints = append(endpoints, Endpoint{Kind: endpoint.Kind, Value: endpoint.Value, Files: files})
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Kind != endpoints[j].Kind {
+			return endpoints[i].Kind < endpoints[j].Kind
+		}
+		return endpoints[i].Value < endpoints[j].Value
+	})
+	return endpoints
+}
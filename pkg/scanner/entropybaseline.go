@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"math"
+	"regexp"
+)
+
+// DefaultEntropyZScore is how many standard deviations above the file's
+// baseline a match's entropy must be with DifferentialEntropy
+const DefaultEntropyZScore = 2.0
+
+// baselineTokenPattern matches the tokens a file's entropy baseline is
+// computed over: identifiers, numbers, and base64-like runs
+var baselineTokenPattern = regexp.MustCompile(`[A-Za-z0-9_$+/=-]{8,}`)
+
+// maxBaselineTokens bounds the tokens sampled for a baseline
+const maxBaselineTokens = 5000
+
+// minBaselineTokens is the fewest tokens a baseline is computed from; files
+// with fewer are too small to tell what stands out
+const minBaselineTokens = 20
+
+// minBaselineStdDev keeps files of near-identical tokens from making every
+// match stand out
+const minBaselineStdDev = 0.1
+
+// entropyBaseline is the mean and standard deviation of the entropy of a
+// file's tokens
+type entropyBaseline struct {
+	Mean   float64
+	StdDev float64
+}
+
+// zScore returns how many standard deviations entropy is above the mean
+func (b entropyBaseline) zScore(entropy float64) float64 {
+	return (entropy - b.Mean) / b.StdDev
+}
+
+// fileEntropyBaseline computes the entropy baseline of content's tokens,
+// or returns nil if it has too few
+func fileEntropyBaseline(content string) *entropyBaseline {
+	tokens := baselineTokenPattern.FindAllString(content, maxBaselineTokens)
+	if len(tokens) < minBaselineTokens {
+		return nil
+	}
+
+	var sum, sumSquares float64
+	for _, token := range tokens {
+		entropy := calculateEntropy(token)
+		sum += entropy
+		sumSquares += entropy * entropy
+	}
+	n := float64(len(tokens))
+	mean := sum / n
+	stdDev := math.Sqrt(math.Max(sumSquares/n-mean*mean, 0))
+	return &entropyBaseline{Mean: mean, StdDev: math.Max(stdDev, minBaselineStdDev)}
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// envVarTag marks findings from values injected as environment variables.
+// Each such finding is also tagged "env:" followed by the variable name.
+const envVarTag = "env-var"
+
+// jsStringPattern matches a quoted string, or a template literal without
+// substitutions
+const jsStringPattern = `("(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.)*'|` + "`(?:[^`\\\\$]|\\\\.)*`" + `)`
+
+var (
+	// envAssignRegex matches assignments such as process.env.API_KEY="..."
+	envAssignRegex = regexp.MustCompile(`(?:process\.env|import\.meta\.env)(?:\.([A-Za-z_$][\w$]*)|\[\s*["']([A-Za-z_$][\w$]*)["']\s*\])\s*=\s*` + jsStringPattern)
+	// envObjectRegex matches the start of an environment object, such as
+	// window.__ENV__ = {...} or a replaced import.meta.env
+	envObjectRegex = regexp.MustCompile(`(?:(?:window|globalThis|self)\.(?:__ENV__|_env_|__env__|__env|env|ENV)|process\.env|import\.meta\.env)\s*=\s*\{`)
+	// envPropertyRegex matches a string property of an object literal
+	envPropertyRegex = regexp.MustCompile(`["']?([A-Za-z_$][\w$]*)["']?\s*:\s*` + jsStringPattern)
+	// envPrefixedRegex matches variables with a bundler's public prefix
+	// wherever they're inlined, such as {VITE_API_KEY:"..."}
+	envPrefixedRegex = regexp.MustCompile(`\b((?:VITE|REACT_APP|NEXT_PUBLIC|NUXT_PUBLIC|NUXT_ENV|VUE_APP|GATSBY|EXPO_PUBLIC)_[A-Za-z0-9_]+)["']?\s*[:=]\s*` + jsStringPattern)
+)
+
+// maxEnvObjectLength bounds how far an environment object literal is read
+const maxEnvObjectLength = 64 * 1024
+
+// envValue is a value assigned to an environment variable in content
+type envValue struct {
+	Name  string
+	Value string
+	// Source is the assignment as it appears in content
+	Source string
+}
+
+// scanEnvVars runs the detection rules over values that bundlers inject as
+// environment variables (process.env.X, import.meta.env, window.__ENV__,
+// and prefixed variables like VITE_*), as NAME="value" text, so they're
+// found whatever syntax surrounds them. Findings are tagged "env-var" and
+// "env:NAME".
+func (s *Scanner) scanEnvVars(file string, content string, reportedMatches map[string]bool) []Finding {
+	var findings []Finding
+	for _, env := range extractEnvValues(content) {
+		for _, finding := range s.scanContent(file, env.Name+"="+strconv.Quote(env.Value), reportedMatches) {
+			finding.Tags = append(append([]string{}, finding.Tags...), envVarTag, "env:"+env.Name)
+			finding.Context = env.Source
+			finding.Line = env.Source
+			finding.CodeSnippet = s.codeSnippet(content, env.Source)
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// extractEnvValues returns the distinct non-empty values assigned to
+// environment variables in content
+func extractEnvValues(content string) []envValue {
+	var values []envValue
+	seen := make(map[string]bool)
+	add := func(name string, literal string, source string) {
+		value := unquoteJSString(literal)
+		key := name + "\x00" + value
+		if strings.TrimSpace(value) == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		values = append(values, envValue{Name: name, Value: value, Source: source})
+	}
+
+	for _, match := range envAssignRegex.FindAllStringSubmatch(content, -1) {
+		name := match[1]
+		if name == "" {
+			name = match[2]
+		}
+		add(name, match[3], match[0])
+	}
+
+	for _, loc := range envObjectRegex.FindAllStringIndex(content, -1) {
+		object := content[loc[1]-1 : objectLiteralEnd(content, loc[1]-1)]
+		for _, match := range envPropertyRegex.FindAllStringSubmatch(object, -1) {
+			add(match[1], match[2], match[0])
+		}
+	}
+
+	for _, match := range envPrefixedRegex.FindAllStringSubmatch(content, -1) {
+		add(match[1], match[2], match[0])
+	}
+
+	return values
+}
+
+// objectLiteralEnd returns the offset just past the brace closing the
+// object literal opened at start, skipping braces inside strings. It stops
+// at maxEnvObjectLength or the end of content.
+func objectLiteralEnd(content string, start int) int {
+	end := len(content)
+	if start+maxEnvObjectLength < end {
+		end = start + maxEnvObjectLength
+	}
+
+	depth := 0
+	var quote byte
+	for i := start; i < end; i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return end
+}
+
+// unquoteJSString returns the value of a JavaScript string literal, or the
+// literal without its quotes if it can't be decoded
+func unquoteJSString(literal string) string {
+	if len(literal) < 2 {
+		return literal
+	}
+	inner := literal[1 : len(literal)-1]
+	if literal[0] == '"' {
+		if value, err := strconv.Unquote(literal); err == nil {
+			return value
+		}
+		return inner
+	}
+	// Single-quoted and template literals decode like double-quoted ones
+	// once their own quotes are no longer escaped
+	inner = strings.ReplaceAll(inner, `\`+string(literal[0]), string(literal[0]))
+	if value, err := strconv.Unquote(`"` + strings.ReplaceAll(inner, `"`, `\"`) + `"`); err == nil {
+		return value
+	}
+	return inner
+}
@@ -0,0 +1,17 @@
+package scanner
+
+import "errors"
+
+// Errors wrapped by scans so callers can tell failures apart with errors.Is
+var (
+	// ErrBrowserLaunch means the browser couldn't be installed or started
+	ErrBrowserLaunch = errors.New("failed to launch browser")
+	// ErrFetch means a page or JavaScript file couldn't be fetched
+	ErrFetch = errors.New("failed to fetch")
+	// ErrInvalidURL means a target or script URL isn't a usable http(s) URL
+	ErrInvalidURL = errors.New("invalid URL")
+	// ErrInvalidOptions means the Options or RunOptions can't be used
+	ErrInvalidOptions = errors.New("invalid options")
+	// ErrBadSignature means a report was altered or signed with another key
+	ErrBadSignature = errors.New("signature verification failed")
+)
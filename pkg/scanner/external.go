@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// externalDetectorTag marks findings reported by the ExternalDetector
+const externalDetectorTag = "external"
+
+// defaultExternalDetectorTimeout bounds each ExternalDetector run when
+// ExternalDetectorTimeout isn't set
+const defaultExternalDetectorTimeout = 30 * time.Second
+
+// maxDetectorStderr bounds how much of a failing detector's stderr is
+// included in the warning
+const maxDetectorStderr = 200
+
+// ExternalFinding is one finding reported by an external detector, which
+// prints a JSON array of them on stdout
+type ExternalFinding struct {
+	// RuleID and Secret are required
+	RuleID      string `json:"rule_id"`
+	Secret      string `json:"secret"`
+	Description string `json:"description,omitempty"`
+	// Line is the matched text around the secret, the secret by default
+	Line     string   `json:"line,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+}
+
+// runExternalDetector pipes content to the ExternalDetector command,
+// exposing the file's URL in $JSWEB_FILE, and parses the findings it prints
+func (s *Scanner) runExternalDetector(file string, content string) ([]ExternalFinding, error) {
+	args := strings.Fields(s.opts.ExternalDetector)
+	if len(args) == 0 {
+		return nil, nil
+	}
+	timeout := s.opts.ExternalDetectorTimeout
+	if timeout <= 0 {
+		timeout = defaultExternalDetectorTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "JSWEB_FILE="+file)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	// The command is killed on timeout, but processes it started may hold
+	// its output open, so don't wait for them
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if len(message) > maxDetectorStderr {
+			message = message[:maxDetectorStderr] + "..."
+		}
+		if message != "" {
+			return nil, fmt.Errorf("%v: %s", err, message)
+		}
+		return nil, err
+	}
+
+	output := bytes.TrimSpace(stdout.Bytes())
+	if len(output) == 0 {
+		return nil, nil
+	}
+	var results []ExternalFinding
+	if err := json.Unmarshal(output, &results); err != nil {
+		return nil, fmt.Errorf("invalid output, expected a JSON array of findings: %v", err)
+	}
+	return results, nil
+}
+
+// scanExternal reports the findings of the ExternalDetector for content.
+// A failing detector is warned about and skipped for the file.
+func (s *Scanner) scanExternal(file string, content string, reportedMatches map[string]bool) []Finding {
+	results, err := s.runExternalDetector(file, content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: External detector failed on %s: %v\n", file, err)
+		return nil
+	}
+
+	var findings []Finding
+	for _, result := range results {
+		if result.RuleID == "" || strings.TrimSpace(result.Secret) == "" {
+			s.debugf("Ignoring external detector finding without a rule_id or secret in %s", file)
+			continue
+		}
+		matchKey := fmt.Sprintf("%s:%s:%s", result.RuleID, file, result.Secret)
+		if reportedMatches[matchKey] {
+			continue
+		}
+
+		rule := config.Rule{
+			ID:          result.RuleID,
+			Description: result.Description,
+			Tags:        append(append([]string{}, result.Tags...), externalDetectorTag),
+		}
+		if rule.Description == "" {
+			rule.Description = "External detector finding"
+		}
+		line := result.Line
+		if line == "" {
+			line = result.Secret
+		}
+		if s.isAllowlisted(line, result.Secret, line, file, rule) {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Description: rule.Description,
+			File:        file,
+			RuleID:      rule.ID,
+			Tags:        rule.Tags,
+			Secret:      result.Secret,
+			Context:     line,
+			Line:        line,
+			CodeSnippet: s.codeSnippet(content, result.Secret),
+			Severity:    strings.ToLower(result.Severity),
+		})
+		reportedMatches[matchKey] = true
+	}
+	return findings
+}
@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FindingFields returns the JSON names of the fields of a Finding, in
+// report order, which ReportInfo.Fields selects from
+func FindingFields() []string {
+	var fields []string
+	t := reflect.TypeOf(Finding{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// ParseFields parses a comma-separated list of finding fields, such as
+// "rule_id,file,secret,line_number", rejecting unknown names
+func ParseFields(list string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, field := range FindingFields() {
+		known[field] = true
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !known[field] {
+			return nil, fmt.Errorf("unknown finding field %q (valid fields: %s)", field, strings.Join(FindingFields(), ", "))
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// projectedFinding is a finding reduced to selected fields, marshaled in
+// the order they were selected. Empty fields a Finding omits stay omitted.
+type projectedFinding struct {
+	fields []string
+	values map[string]json.RawMessage
+}
+
+// MarshalJSON writes the selected fields as a JSON object
+func (p projectedFinding) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for _, field := range p.fields {
+		value, ok := p.values[field]
+		if !ok {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(field)
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// projectFinding reduces finding to fields, or returns it whole when no
+// fields are selected
+func projectFinding(finding Finding, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return finding, nil
+	}
+	data, err := json.Marshal(finding)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return projectedFinding{fields: fields, values: values}, nil
+}
+
+// projectFindings reduces each finding to fields
+func projectFindings(findings []Finding, fields []string) (interface{}, error) {
+	if len(fields) == 0 || findings == nil {
+		return findings, nil
+	}
+	projected := make([]interface{}, 0, len(findings))
+	for _, finding := range findings {
+		p, err := projectFinding(finding, fields)
+		if err != nil {
+			return nil, err
+		}
+		projected = append(projected, p)
+	}
+	return projected, nil
+}
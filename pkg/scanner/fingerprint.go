@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// Fingerprint returns a stable identity for a finding of ruleID in file. It
+// deliberately ignores line numbers and surrounding context, so a secret
+// moving within a rebuilt bundle keeps the same fingerprint.
+func Fingerprint(ruleID string, file string, secret string) string {
+	hash := sha256.Sum256([]byte(ruleID + "\x00" + NormalizeFile(file) + "\x00" + secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// NormalizeFile reduces a file URL to the parts that identify the file,
+// lowercasing the scheme and host and dropping the query string (often a
+// cache-busting version) and fragment
+func NormalizeFile(file string) string {
+	u, err := url.Parse(file)
+	if err != nil || u.Host == "" {
+		return file
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
+
+// secretTrailingNoise is punctuation loose capture groups pick up after a
+// secret in minified code
+const secretTrailingNoise = ";,.:)]}"
+
+// secretQuotes are the quotes a secret's string literal is delimited by
+const secretQuotes = "'\"`"
+
+// NormalizeSecret strips the whitespace, quotes, and trailing punctuation
+// imprecise capture groups include around a secret, so the same secret
+// captured differently across builds dedupes and fingerprints the same
+func NormalizeSecret(secret string) string {
+	for {
+		trimmed := strings.TrimSpace(secret)
+		trimmed = strings.TrimRight(trimmed, secretTrailingNoise)
+		trimmed = strings.Trim(trimmed, secretQuotes)
+		if trimmed == secret {
+			return secret
+		}
+		secret = trimmed
+	}
+}
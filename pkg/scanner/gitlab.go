@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// GitLabFormat writes a GitLab Secret Detection report, which GitLab CI
+// picks up as a secret_detection artifact for the Security Dashboard
+const GitLabFormat = "gitlab"
+
+// gitLabSchemaVersion is the version of the GitLab security report schema
+// the report follows
+const gitLabSchemaVersion = "15.0.7"
+
+// gitLabTimeFormat is the time format of the report's scan times
+const gitLabTimeFormat = "2006-01-02T15:04:05"
+
+// gitLabSeverities maps finding severities to GitLab's
+var gitLabSeverities = map[string]string{
+	SeverityLow:      "Low",
+	SeverityMedium:   "Medium",
+	SeverityHigh:     "High",
+	SeverityCritical: "Critical",
+}
+
+// gitLabReport is a GitLab Secret Detection report
+type gitLabReport struct {
+	Version         string                `json:"version"`
+	Vulnerabilities []gitLabVulnerability `json:"vulnerabilities"`
+	Scan            gitLabScan            `json:"scan"`
+}
+
+type gitLabVulnerability struct {
+	ID          string             `json:"id"`
+	Category    string             `json:"category"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Severity    string             `json:"severity"`
+	Solution    string             `json:"solution,omitempty"`
+	Scanner     gitLabTool         `json:"scanner"`
+	Location    gitLabLocation     `json:"location"`
+	Identifiers []gitLabIdentifier `json:"identifiers"`
+}
+
+type gitLabLocation struct {
+	File      string       `json:"file"`
+	StartLine int          `json:"start_line,omitempty"`
+	EndLine   int          `json:"end_line,omitempty"`
+	Commit    gitLabCommit `json:"commit"`
+}
+
+// gitLabCommit is required by the schema. Findings come from deployed
+// files rather than a commit, so its SHA is a placeholder.
+type gitLabCommit struct {
+	SHA string `json:"sha"`
+}
+
+type gitLabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type gitLabTool struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Version string        `json:"version,omitempty"`
+	Vendor  *gitLabVendor `json:"vendor,omitempty"`
+}
+
+type gitLabVendor struct {
+	Name string `json:"name"`
+}
+
+type gitLabScan struct {
+	Analyzer  gitLabTool `json:"analyzer"`
+	Scanner   gitLabTool `json:"scanner"`
+	Type      string     `json:"type"`
+	StartTime string     `json:"start_time"`
+	EndTime   string     `json:"end_time"`
+	Status    string     `json:"status"`
+}
+
+// gitLabVulnerabilityOf maps a finding to a GitLab vulnerability, keyed by
+// its fingerprint so the dashboard tracks it across pipelines
+func gitLabVulnerabilityOf(finding Finding) gitLabVulnerability {
+	id := finding.Fingerprint
+	if id == "" {
+		id = Fingerprint(finding.RuleID, finding.File, finding.Secret)
+	}
+	severity, ok := gitLabSeverities[finding.Severity]
+	if !ok {
+		severity = "Unknown"
+	}
+	return gitLabVulnerability{
+		ID:          id,
+		Category:    "secret_detection",
+		Name:        finding.Description,
+		Description: fmt.Sprintf("%s detected in %s: %s", finding.Description, finding.File, redactSecret(finding.Secret)),
+		Severity:    severity,
+		Solution:    finding.Remediation,
+		Scanner:     gitLabTool{ID: "jsweb", Name: "JSWeb"},
+		Location: gitLabLocation{
+			File:      finding.File,
+			StartLine: finding.LineNumber,
+			EndLine:   finding.LineNumber,
+			Commit:    gitLabCommit{SHA: "0000000"},
+		},
+		Identifiers: []gitLabIdentifier{{
+			Type:  "gitleaks_rule_id",
+			Name:  "Gitleaks rule ID " + finding.RuleID,
+			Value: finding.RuleID,
+		}},
+	}
+}
+
+// writeGitLab writes findings as a GitLab Secret Detection report. Secrets
+// are redacted, since the report is shown in the GitLab UI.
+func writeGitLab(w io.Writer, findings []Finding, info ReportInfo) error {
+	start := info.Timestamp
+	if start.IsZero() {
+		start = time.Now()
+	}
+	tool := gitLabTool{ID: "jsweb", Name: "JSWeb", Version: info.Version, Vendor: &gitLabVendor{Name: "JSWeb"}}
+	report := gitLabReport{
+		Version:         gitLabSchemaVersion,
+		Vulnerabilities: []gitLabVulnerability{},
+		Scan: gitLabScan{
+			Analyzer:  tool,
+			Scanner:   tool,
+			Type:      "secret_detection",
+			StartTime: start.UTC().Format(gitLabTimeFormat),
+			EndTime:   time.Now().UTC().Format(gitLabTimeFormat),
+			Status:    "success",
+		},
+	}
+	for _, finding := range findings {
+		report.Vulnerabilities = append(report.Vulnerabilities, gitLabVulnerabilityOf(finding))
+	}
+
+	jsonData, err := marshalJSON(report, info.Compact)
+	if err != nil {
+		return fmt.Errorf("failed to marshal GitLab report: %v", err)
+	}
+	if _, err := fmt.Fprintln(w, string(jsonData)); err != nil {
+		return fmt.Errorf("failed to write GitLab report: %v", err)
+	}
+	return nil
+}
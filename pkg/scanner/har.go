@@ -0,0 +1,216 @@
+package scanner
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nautical/jsweb/pkg/utils"
+)
+
+// harFile is the subset of the HAR 1.2 format needed to scan responses
+type harFile struct {
+	Log struct {
+		Pages []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"pages"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+// harEntry is a single request and response captured in a HAR file
+type harEntry struct {
+	PageRef string `json:"pageref"`
+	Request struct {
+		URL string `json:"url"`
+	} `json:"request"`
+	Response struct {
+		Status  int `json:"status"`
+		Headers []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+// ScanHAR scans the JavaScript responses captured in a HAR file without a
+// browser or network access. Findings name the original request URL as
+// their file and the page that loaded it as their target. The scan stops
+// with ctx's error once ctx is done.
+func (s *Scanner) ScanHAR(ctx context.Context, path string) error {
+	if s.optionsErr != nil {
+		return s.optionsErr
+	}
+
+	start := time.Now()
+	err := s.scanHAR(ctx, path)
+	s.recordRun(time.Since(start), err)
+	return err
+}
+
+// scanHAR performs the scan for ScanHAR
+func (s *Scanner) scanHAR(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read HAR file: %v", err)
+	}
+
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return fmt.Errorf("failed to parse HAR file: %v", err)
+	}
+
+	// Browsers record the page URL as the page title
+	pageURLs := make(map[string]string)
+	for _, page := range har.Log.Pages {
+		if u, err := url.Parse(page.Title); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			pageURLs[page.ID] = page.Title
+		}
+	}
+
+	s.debugf("Scanning %d HAR entries from %s", len(har.Log.Entries), path)
+	for i, entry := range har.Log.Entries {
+		if err := ctx.Err(); err != nil {
+			remaining := 0
+			for _, entry := range har.Log.Entries[i:] {
+				content, _ := entry.body()
+				if s.harEntryScannable(entry, content) {
+					remaining++
+				}
+			}
+			s.addUnchecked(remaining, 0)
+			return err
+		}
+		if s.limitReached() {
+			break
+		}
+		s.checkHAREntry(entry, pageURLs[entry.PageRef])
+	}
+
+	return nil
+}
+
+// responseHeader returns the value of the last response header named name,
+// ignoring case
+func (entry harEntry) responseHeader(name string) string {
+	value := ""
+	for _, header := range entry.Response.Headers {
+		if strings.EqualFold(header.Name, name) {
+			value = header.Value
+		}
+	}
+	return value
+}
+
+// body returns the captured response body, decoding base64 content
+func (entry harEntry) body() ([]byte, error) {
+	if entry.Response.Content.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+	}
+	return []byte(entry.Response.Content.Text), nil
+}
+
+// contentType returns the entry's response content type, from the captured
+// content or else its Content-Type header
+func (entry harEntry) contentType() string {
+	if entry.Response.Content.MimeType != "" {
+		return entry.Response.Content.MimeType
+	}
+	return entry.responseHeader("Content-Type")
+}
+
+// harEntryScannable checks if the response of a HAR entry would have been
+// fetched in a live scan
+func (s *Scanner) harEntryScannable(entry harEntry, content []byte) bool {
+	fileURL, contentType := entry.Request.URL, entry.contentType()
+	return s.isScannableContentType(contentType) || s.isScannableFile(fileURL) || s.sniffScannable(contentType, fileURL, content)
+}
+
+// checkHAREntry scans the response of a HAR entry, applying the same filters
+// as files fetched during a live scan
+func (s *Scanner) checkHAREntry(entry harEntry, target string) {
+	fileURL := entry.Request.URL
+	contentType := entry.contentType()
+	content, bodyErr := entry.body()
+
+	// Only entries that would have been fetched in a live scan are reported
+	if !s.harEntryScannable(entry, content) {
+		return
+	}
+
+	report := FileReport{URL: fileURL, Status: FileScanned, StatusCode: entry.Response.Status, ContentType: contentType}
+	if s.opts.CaptureHeaders {
+		report.Headers = captureHeaders(entry.responseHeader)
+	}
+	defer func() { s.recordFile(report) }()
+	skip := func(reason string) {
+		report.Status = FileSkipped
+		report.Reason = reason
+	}
+
+	if s.isIgnored(fileURL) {
+		s.debugf("Skipping ignored file %s", fileURL)
+		skip("matched ignore pattern")
+		return
+	}
+	if s.isExcluded(fileURL) {
+		s.debugf("Skipping file %s not matching an include pattern", fileURL)
+		skip("matched no include pattern")
+		return
+	}
+	if utils.IsThirdPartyDomain(fileURL) {
+		skip("third-party domain")
+		return
+	}
+	if entry.Response.Status < 200 || entry.Response.Status > 299 {
+		skip(fmt.Sprintf("unexpected status %d", entry.Response.Status))
+		return
+	}
+	if !s.isScannableContentType(contentType) {
+		switch {
+		case s.sniffScannable(contentType, fileURL, content):
+			s.debugf("Scanning %s with generic content type %q as JavaScript", fileURL, contentType)
+		case s.opts.AllowAnyContentType && s.isScannableFile(fileURL):
+			s.debugf("Scanning %s despite unexpected content type %q", fileURL, contentType)
+		default:
+			skip(fmt.Sprintf("unexpected content type %q", contentType))
+			return
+		}
+	}
+
+	if bodyErr != nil {
+		report.Status = FileError
+		report.Reason = fmt.Sprintf("failed to decode base64 response body: %v", bodyErr)
+		return
+	}
+	report.BytesRead = int64(len(content))
+	if len(content) == 0 {
+		skip("response body not captured")
+		return
+	}
+
+	if maxSize := s.opts.MaxFileSize; maxSize > 0 && int64(len(content)) > maxSize {
+		fmt.Fprintf(os.Stderr, "Warning: Skipping %s: size %d bytes exceeds limit of %d bytes\n", fileURL, len(content), maxSize)
+		skip(fmt.Sprintf("size %d bytes exceeds limit of %d bytes", len(content), maxSize))
+		return
+	}
+
+	if original := s.duplicateOf(fileURL, content); original != "" {
+		s.debugf("Skipping %s: same content as %s", fileURL, original)
+		skip("same content as " + original)
+		return
+	}
+
+	report.BytesScanned, report.MatchDuration = s.checkContent(fileURL, target, contentType, content)
+}
@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+func TestScanHARStopsWhenCancelled(t *testing.T) {
+	har := `{"log": {"entries": [
+		{"request": {"url": "https://example.com/app.js"}, "response": {"status": 200, "content": {"mimeType": "application/javascript", "text": "var a = 1;"}}},
+		{"request": {"url": "https://example.com/logo.png"}, "response": {"status": 200, "content": {"mimeType": "image/png", "text": ""}}}
+	]}}`
+	path := filepath.Join(t.TempDir(), "capture.har")
+	if err := os.WriteFile(path, []byte(har), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := New(&config.Config{}, Options{})
+	if err := s.ScanHAR(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ScanHAR error = %v, want context.Canceled", err)
+	}
+	partial := s.Partial()
+	if partial == nil {
+		t.Fatal("a cancelled HAR scan wasn't reported as partial")
+	}
+	if partial.FilesRemaining != 1 {
+		t.Errorf("files remaining = %d, want 1 (the image isn't a file to scan)", partial.FilesRemaining)
+	}
+}
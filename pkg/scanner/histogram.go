@@ -0,0 +1,153 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The entropy histogram has buckets entropyBucketWidth bits wide from 0 up
+// to 8 bits, the most a byte-mode secret can have. Higher rune-mode
+// entropies count in the last bucket.
+const (
+	entropyBucketWidth = 0.5
+	entropyBuckets     = 16
+)
+
+// histogramBarWidth is the length of the largest bucket's bar
+const histogramBarWidth = 40
+
+// EntropyHistogram counts a rule's matches by secret entropy
+type EntropyHistogram struct {
+	RuleID string `json:"rule_id"`
+	// Buckets counts the matches with entropy in [i*0.5, (i+1)*0.5) bits
+	Buckets [entropyBuckets]int `json:"buckets"`
+}
+
+// add counts a match with the given entropy
+func (h *EntropyHistogram) add(entropy float64) {
+	bucket := int(entropy / entropyBucketWidth)
+	if bucket >= entropyBuckets {
+		bucket = entropyBuckets - 1
+	}
+	if bucket < 0 {
+		bucket = 0
+	}
+	h.Buckets[bucket]++
+}
+
+// total returns the number of matches counted
+func (h *EntropyHistogram) total() int {
+	total := 0
+	for _, count := range h.Buckets {
+		total += count
+	}
+	return total
+}
+
+// addEntropyHistograms adds the histograms from one scan of content to the
+// totals
+func (s *Scanner) addEntropyHistograms(histograms map[string]*EntropyHistogram) {
+	if len(histograms) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entropyHistograms == nil {
+		s.entropyHistograms = make(map[string]*EntropyHistogram)
+	}
+	for ruleID, histogram := range histograms {
+		total, ok := s.entropyHistograms[ruleID]
+		if !ok {
+			total = &EntropyHistogram{RuleID: ruleID}
+			s.entropyHistograms[ruleID] = total
+		}
+		for i, count := range histogram.Buckets {
+			total.Buckets[i] += count
+		}
+	}
+}
+
+// GetEntropyHistograms returns the entropy histogram of every rule that
+// matched with Options.EntropyHistogram set, sorted by rule ID
+func (s *Scanner) GetEntropyHistograms() []EntropyHistogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var histograms []EntropyHistogram
+	for _, histogram := range s.entropyHistograms {
+		histograms = append(histograms, *histogram)
+	}
+	sort.Slice(histograms, func(i, j int) bool {
+		return histograms[i].RuleID < histograms[j].RuleID
+	})
+	return histograms
+}
+
+// PrintEntropyHistogram writes the entropy histogram of all rule matches to
+// w, followed by one per rule if perRule is set
+func (s *Scanner) PrintEntropyHistogram(w io.Writer, perRule bool) error {
+	histograms := s.GetEntropyHistograms()
+
+	combined := EntropyHistogram{}
+	for _, histogram := range histograms {
+		for i, count := range histogram.Buckets {
+			combined.Buckets[i] += count
+		}
+	}
+	if err := writeHistogram(w, "all rules", combined); err != nil {
+		return err
+	}
+	if !perRule {
+		return nil
+	}
+	for _, histogram := range histograms {
+		if err := writeHistogram(w, histogram.RuleID, histogram); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHistogram draws one histogram with a bar per bucket, leaving out the
+// empty buckets before the first and after the last match
+func writeHistogram(w io.Writer, title string, histogram EntropyHistogram) error {
+	total := histogram.total()
+	var b strings.Builder
+	matches := "matches"
+	if total == 1 {
+		matches = "match"
+	}
+	fmt.Fprintf(&b, "\nEntropy of %s (%d %s)\n", title, total, matches)
+
+	first, last, largest := -1, -1, 0
+	for i, count := range histogram.Buckets {
+		if count == 0 {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+		if count > largest {
+			largest = count
+		}
+	}
+	for i := first; i >= 0 && i <= last; i++ {
+		count := histogram.Buckets[i]
+		bar := (count*histogramBarWidth + largest - 1) / largest
+		low := float64(i) * entropyBucketWidth
+		label := fmt.Sprintf("%.1f-%.1f", low, low+entropyBucketWidth)
+		if i == entropyBuckets-1 {
+			label = fmt.Sprintf("%.1f+", low)
+		}
+		fmt.Fprintf(&b, "  %-8s %-*s %d\n", label, histogramBarWidth, strings.Repeat("#", bar), count)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write entropy histogram: %v", err)
+	}
+	return nil
+}
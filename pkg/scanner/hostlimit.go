@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"net/url"
+	"sync"
+)
+
+// acquireHost blocks until fewer than PerHostConcurrency fetches are in
+// flight to fileURL's host, and returns a function that releases the slot.
+// The release function may be called more than once.
+func (s *Scanner) acquireHost(fileURL string) func() {
+	if s.opts.PerHostConcurrency <= 0 {
+		return func() {}
+	}
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return func() {}
+	}
+
+	s.mu.Lock()
+	if s.hostSlots == nil {
+		s.hostSlots = make(map[string]chan struct{})
+	}
+	slots, ok := s.hostSlots[u.Host]
+	if !ok {
+		slots = make(chan struct{}, s.opts.PerHostConcurrency)
+		s.hostSlots[u.Host] = slots
+	}
+	s.mu.Unlock()
+
+	slots <- struct{}{}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-slots })
+	}
+}
@@ -0,0 +1,227 @@
+package scanner
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// htmlFile groups the findings of one file in the HTML report
+type htmlFile struct {
+	File     string
+	Findings []htmlFinding
+}
+
+// htmlFinding is a finding prepared for display in the HTML report
+type htmlFinding struct {
+	Finding
+	RedactedSecret string
+	SnippetBefore  string
+	SnippetMatch   string
+	SnippetAfter   string
+}
+
+// htmlSection is a titled group of findings in the HTML report
+type htmlSection struct {
+	Title string
+	Files []htmlFile
+}
+
+// htmlStat is a labeled count shown in the HTML report header
+type htmlStat struct {
+	Label string
+	Count int
+}
+
+// htmlReport is the data rendered by htmlTemplate
+type htmlReport struct {
+	Targets    []string
+	Version    string
+	ConfigHash string
+	Timestamp  string
+	Stats      []htmlStat
+	Sections   []htmlSection
+	Skipped    []FileReport
+	Endpoints  []Endpoint
+}
+
+// htmlTemplate renders a self-contained report with no external resources
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>JSWeb Report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+h1 { margin-bottom: 0.2em; }
+.meta { color: #59636e; margin-bottom: 2em; }
+.file { border: 1px solid #d1d9e0; border-radius: 6px; margin-bottom: 1.5em; }
+.file h2 { font-size: 1em; margin: 0; padding: 0.6em 1em; background: #f6f8fa; border-bottom: 1px solid #d1d9e0; word-break: break-all; }
+.finding { padding: 0.6em 1em; border-bottom: 1px solid #eef1f4; }
+.finding:last-child { border-bottom: none; }
+.rule { font-weight: 600; }
+.tag { display: inline-block; background: #ddf4ff; border-radius: 1em; padding: 0 0.6em; margin-left: 0.3em; font-size: 0.85em; }
+code, pre { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 0.9em; }
+pre { background: #f6f8fa; padding: 0.8em; overflow-x: auto; white-space: pre-wrap; word-break: break-all; }
+mark { background: #ffd8b5; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.4em 0.6em; border-bottom: 1px solid #d1d9e0; word-break: break-all; }
+.empty { color: #1a7f37; }
+</style>
+</head>
+<body>
+<h1>JSWeb Report</h1>
+<div class="meta">
+{{range .Targets}}<div>Target: <code>{{.}}</code></div>{{end}}
+<div>Scanned: {{.Timestamp}}</div>
+<div>JSWeb version: {{.Version}}</div>
+{{if .ConfigHash}}<div>Config hash: <code>{{.ConfigHash}}</code></div>{{end}}
+{{range .Stats}}<div>{{.Label}}: {{.Count}}</div>{{end}}
+</div>
+{{range .Sections}}
+{{if .Title}}<h2>{{.Title}}</h2>{{end}}
+{{if not .Files}}<p class="empty">No findings.</p>{{end}}
+{{range .Files}}
+<div class="file">
+<h2>{{.File}}</h2>
+{{range .Findings}}
+<div class="finding">
+<div><span class="rule">{{.Description}}</span> <code>{{.RuleID}}</code>{{range .Tags}}<span class="tag">{{.}}</span>{{end}}</div>
+<div>Secret: <code>{{.RedactedSecret}}</code>{{if .Entropy}} &middot; Entropy: {{printf "%.2f" .Entropy}}{{end}}</div>
+<details>
+<summary>Code snippet</summary>
+<pre>{{.SnippetBefore}}<mark>{{.SnippetMatch}}</mark>{{.SnippetAfter}}</pre>
+</details>
+</div>
+{{end}}
+</div>
+{{end}}
+{{end}}
+{{if .Skipped}}
+<h2>Skipped files</h2>
+<table>
+<tr><th>URL</th><th>Status</th><th>HTTP status</th><th>Content type</th><th>Bytes read</th><th>Reason</th></tr>
+{{range .Skipped}}<tr><td>{{.URL}}</td><td>{{.Status}}</td><td>{{if .StatusCode}}{{.StatusCode}}{{end}}</td><td>{{.ContentType}}</td><td>{{.BytesRead}}</td><td>{{.Reason}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Endpoints}}
+<h2>Endpoints</h2>
+<table>
+<tr><th>Kind</th><th>Endpoint</th><th>Files</th></tr>
+{{range .Endpoints}}<tr><td>{{.Kind}}</td><td><code>{{.Value}}</code></td><td>{{range .Files}}<div>{{.}}</div>{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// writeHTML renders findings grouped by file (and any skipped files and
+// endpoints) as a self-contained HTML report
+func writeHTML(w io.Writer, data reportData, info ReportInfo) error {
+	report := newHTMLReport(info)
+	report.Stats = []htmlStat{{Label: "Findings", Count: len(data.Findings)}}
+	if data.TotalFindings > 0 {
+		report.Stats = append(report.Stats, htmlStat{Label: "Findings discovered (report truncated)", Count: data.TotalFindings})
+	}
+	if data.Sample != nil {
+		report.Stats = append(report.Stats,
+			htmlStat{Label: fmt.Sprintf("Files discovered (sampled at rate %g)", data.Sample.Rate), Count: data.Sample.TotalFiles},
+			htmlStat{Label: "Files sampled", Count: data.Sample.SampledFiles})
+	}
+	if data.Partial != nil {
+		report.Stats = append(report.Stats,
+			htmlStat{Label: "Files scanned (partial scan)", Count: data.Partial.FilesScanned},
+			htmlStat{Label: "Files remaining", Count: data.Partial.FilesRemaining},
+			htmlStat{Label: "Pages remaining", Count: data.Partial.PagesRemaining})
+	}
+	report.Sections = []htmlSection{{Files: groupHTMLFindings(data.Findings)}}
+	report.Skipped = data.Skipped
+	report.Endpoints = data.Endpoints
+	return renderHTML(w, report)
+}
+
+// writeHTMLDiff renders the added, removed, and unchanged findings of a diff
+// as a self-contained HTML report
+func writeHTMLDiff(w io.Writer, diff DiffResult, info ReportInfo) error {
+	report := newHTMLReport(info)
+	report.Stats = []htmlStat{
+		{Label: "Added", Count: len(diff.Added)},
+		{Label: "Removed", Count: len(diff.Removed)},
+		{Label: "Unchanged", Count: len(diff.Unchanged)},
+	}
+	report.Sections = []htmlSection{
+		{Title: "Added", Files: groupHTMLFindings(diff.Added)},
+		{Title: "Removed", Files: groupHTMLFindings(diff.Removed)},
+		{Title: "Unchanged", Files: groupHTMLFindings(diff.Unchanged)},
+	}
+	return renderHTML(w, report)
+}
+
+// newHTMLReport creates a report carrying the scan's header information
+func newHTMLReport(info ReportInfo) htmlReport {
+	timestamp := info.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	return htmlReport{
+		Targets:    info.Targets,
+		Version:    info.Version,
+		ConfigHash: info.ConfigHash,
+		Timestamp:  timestamp.Format(time.RFC3339),
+	}
+}
+
+// groupHTMLFindings groups findings by file, keeping their order
+func groupHTMLFindings(findings []Finding) []htmlFile {
+	var files []htmlFile
+	fileIndex := make(map[string]int)
+	for _, finding := range findings {
+		idx, ok := fileIndex[finding.File]
+		if !ok {
+			idx = len(files)
+			fileIndex[finding.File] = idx
+			files = append(files, htmlFile{File: finding.File})
+		}
+		files[idx].Findings = append(files[idx].Findings, newHTMLFinding(finding))
+	}
+	return files
+}
+
+// renderHTML executes htmlTemplate for report
+func renderHTML(w io.Writer, report htmlReport) error {
+	if err := htmlTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %v", err)
+	}
+	return nil
+}
+
+// newHTMLFinding redacts the secret throughout the snippet and splits the
+// snippet around its first occurrence so it can be highlighted
+func newHTMLFinding(finding Finding) htmlFinding {
+	redacted := redactSecret(finding.Secret)
+	entry := htmlFinding{
+		Finding:        finding,
+		RedactedSecret: redacted,
+		SnippetBefore:  finding.CodeSnippet,
+	}
+
+	if finding.Secret == "" {
+		return entry
+	}
+
+	snippet := finding.CodeSnippet
+	pos := strings.Index(snippet, finding.Secret)
+	if pos == -1 {
+		return entry
+	}
+
+	before := snippet[:pos]
+	after := snippet[pos+len(finding.Secret):]
+	entry.SnippetBefore = strings.ReplaceAll(before, finding.Secret, redacted)
+	entry.SnippetMatch = redacted
+	entry.SnippetAfter = strings.ReplaceAll(after, finding.Secret, redacted)
+	return entry
+}
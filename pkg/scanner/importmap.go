@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// importMapsScript returns the text of the page's import maps
+const importMapsScript = `() => Array.from(document.querySelectorAll('script[type="importmap"]')).map(script => script.textContent)`
+
+// importMap is the JSON of a <script type="importmap">. Values are kept
+// loose so one malformed entry doesn't hide the rest.
+type importMap struct {
+	Imports map[string]interface{}            `json:"imports"`
+	Scopes  map[string]map[string]interface{} `json:"scopes"`
+}
+
+// importMapURLs returns the module URLs an import map's imports and scopes
+// map specifiers to, unresolved and in a stable order. Prefix mappings
+// ending in "/" name directories rather than files and are left out.
+func importMapURLs(text string) []string {
+	var m importMap
+	if err := json.Unmarshal([]byte(text), &m); err != nil {
+		return nil
+	}
+
+	var urls []string
+	add := func(mappings map[string]interface{}) {
+		for _, value := range mappings {
+			if target, ok := value.(string); ok && target != "" && !strings.HasSuffix(target, "/") {
+				urls = append(urls, target)
+			}
+		}
+	}
+	add(m.Imports)
+	for _, mappings := range m.Scopes {
+		add(mappings)
+	}
+	sort.Strings(urls)
+	return urls
+}
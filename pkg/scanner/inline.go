@@ -0,0 +1,167 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// inlineScript is a script whose content came with the page instead of
+// from a URL the scanner can fetch: a data: URI or a blob: URL
+type inlineScript struct {
+	// Ref names the page and the script's source, such as
+	// "https://example.com/ [data:text/javascript sha256:3fa2b1c0d9e8]",
+	// and is used as the file of its findings
+	Ref     string
+	Content []byte
+}
+
+// blobScript reads a blob: URL inside the page that created it
+const blobScript = `async url => {
+	const response = await fetch(url);
+	return await response.text();
+}`
+
+// isDataURI checks if a script source is a data: URI
+func isDataURI(src string) bool {
+	src = strings.TrimSpace(src)
+	return len(src) >= 5 && strings.EqualFold(src[:5], "data:")
+}
+
+// isBlobURL checks if a script source is a blob: URL
+func isBlobURL(src string) bool {
+	src = strings.TrimSpace(src)
+	return len(src) >= 5 && strings.EqualFold(src[:5], "blob:")
+}
+
+// parseDataURI decodes a data: URI into its media type, without
+// parameters, and its base64 or percent-encoded content
+func parseDataURI(uri string) (string, []byte, error) {
+	uri = strings.TrimSpace(uri)
+	if !isDataURI(uri) {
+		return "", nil, fmt.Errorf("not a data URI")
+	}
+	meta, data, ok := strings.Cut(uri[5:], ",")
+	if !ok {
+		return "", nil, fmt.Errorf("data URI has no comma")
+	}
+
+	encoded := false
+	if strings.HasSuffix(strings.ToLower(meta), ";base64") {
+		encoded = true
+		meta = meta[:len(meta)-len(";base64")]
+	}
+	mediaType, _, _ := strings.Cut(meta, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		mediaType = "text/plain"
+	}
+
+	unescaped, err := url.PathUnescape(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid percent-encoding: %v", err)
+	}
+	if !encoded {
+		return mediaType, []byte(unescaped), nil
+	}
+	unescaped = strings.Join(strings.Fields(unescaped), "")
+	content, err := base64.StdEncoding.DecodeString(unescaped)
+	if err != nil {
+		content, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(unescaped, "="))
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid base64: %v", err)
+	}
+	return mediaType, content, nil
+}
+
+// isScriptMediaType checks if a data: URI's media type holds JavaScript,
+// or JSON when ScanJSON is set. Browsers run data: scripts whatever their
+// type, so text/plain, the default, counts too.
+func (s *Scanner) isScriptMediaType(mediaType string) bool {
+	return strings.Contains(mediaType, "javascript") || strings.Contains(mediaType, "ecmascript") ||
+		mediaType == "text/plain" || (s.opts.ScanJSON && strings.Contains(mediaType, "json"))
+}
+
+// addDataURI queues the content of a data: URI script found on pageURL
+func (s *Scanner) addDataURI(pageURL string, uri string) {
+	mediaType, content, err := parseDataURI(uri)
+	if err != nil {
+		s.debugf("Skipping data URI script on %s: %v", pageURL, err)
+		return
+	}
+	if !s.isScriptMediaType(mediaType) {
+		s.debugf("Skipping data URI script on %s: media type %s", pageURL, mediaType)
+		return
+	}
+	s.addInlineScript(pageURL, "data:"+mediaType, content)
+}
+
+// fetchBlobScripts queues the content of blob: script URLs, read inside
+// the page that created them since they can't be fetched from outside it
+func (s *Scanner) fetchBlobScripts(page playwright.Page, urls []string) {
+	for _, blobURL := range urls {
+		result, err := page.Evaluate(blobScript, blobURL)
+		if err != nil {
+			s.debugf("Failed to read %s: %v", blobURL, err)
+			continue
+		}
+		if text, ok := result.(string); ok {
+			s.addInlineScript(page.URL(), "blob", []byte(text))
+		}
+	}
+}
+
+// addInlineScript queues a script found on pageURL, once per distinct
+// content across the scan
+func (s *Scanner) addInlineScript(pageURL string, source string, content []byte) {
+	if len(content) == 0 {
+		return
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seenFiles == nil {
+		s.seenFiles = make(map[string]bool)
+	}
+	key := "inline:" + hash
+	if s.seenFiles[key] {
+		return
+	}
+	s.seenFiles[key] = true
+	s.pendingInline = append(s.pendingInline, inlineScript{
+		Ref:     fmt.Sprintf("%s [%s sha256:%s]", pageURL, source, hash[:12]),
+		Content: content,
+	})
+}
+
+// checkInlineScripts scans the queued inline scripts for secrets,
+// attributing findings to target
+func (s *Scanner) checkInlineScripts(target string) {
+	s.mu.Lock()
+	scripts := s.pendingInline
+	s.pendingInline = nil
+	s.mu.Unlock()
+
+	if len(scripts) > 0 {
+		s.debugf("Scanning %d inline scripts", len(scripts))
+	}
+	for _, script := range scripts {
+		if s.limitReached() {
+			return
+		}
+		if maxSize := s.opts.MaxFileSize; maxSize > 0 && int64(len(script.Content)) > maxSize {
+			fmt.Fprintf(os.Stderr, "Warning: Skipping %s: size %d bytes exceeds limit of %d bytes\n", script.Ref, len(script.Content), maxSize)
+			continue
+		}
+		s.checkContent(script.Ref, target, "", script.Content)
+	}
+}
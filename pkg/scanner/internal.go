@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// Rule IDs reported for internal infrastructure references
+const (
+	privateIPRuleID        = "jsweb-private-ip"
+	internalHostnameRuleID = "jsweb-internal-hostname"
+	cloudMetadataRuleID    = "jsweb-cloud-metadata"
+)
+
+var (
+	// privateIPRegex matches RFC 1918 IPv4 addresses
+	privateIPRegex = regexp.MustCompile(`(?:10\.\d{1,3}|172\.(?:1[6-9]|2\d|3[01])|192\.168)\.\d{1,3}\.\d{1,3}`)
+	// internalHostnameRegex matches .internal and .local hostnames that
+	// appear in a string or URL, so property accesses like a.local don't
+	internalHostnameRegex = regexp.MustCompile("(?i)[\"'`/@]((?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\\.)+(?:internal|local))(?:[\"'`:/?#]|$)")
+	// cloudMetadataRegex matches cloud instance metadata endpoints
+	cloudMetadataRegex = regexp.MustCompile(`(?i)169\.254\.169\.254|metadata\.google\.internal|fd00:ec2::254|100\.100\.100\.200`)
+)
+
+// internalRules are the pseudo-rules used to apply allowlists to internal
+// infrastructure findings
+var internalRules = map[string]config.Rule{
+	privateIPRuleID: {
+		ID:          privateIPRuleID,
+		Description: "Private IP address",
+		Tags:        []string{"internal", "ip"},
+	},
+	internalHostnameRuleID: {
+		ID:          internalHostnameRuleID,
+		Description: "Internal hostname",
+		Tags:        []string{"internal", "hostname"},
+	},
+	cloudMetadataRuleID: {
+		ID:          cloudMetadataRuleID,
+		Description: "Cloud metadata endpoint",
+		Tags:        []string{"internal", "cloud"},
+	},
+}
+
+// scanInternal finds private IP addresses, internal hostnames, and cloud
+// metadata endpoints in content and reports them with low severity
+func (s *Scanner) scanInternal(file string, content string, reportedMatches map[string]bool) []Finding {
+	var findings []Finding
+	add := func(ruleID string, value string) {
+		matchKey := fmt.Sprintf("%s:%s:%s", ruleID, file, value)
+		if reportedMatches[matchKey] {
+			return
+		}
+
+		rule := internalRules[ruleID]
+		if s.isAllowlisted(value, value, lineContaining(content, value), file, rule) {
+			return
+		}
+
+		findings = append(findings, Finding{
+			Description: rule.Description,
+			File:        file,
+			RuleID:      ruleID,
+			Tags:        rule.Tags,
+			Secret:      value,
+			Context:     value,
+			Line:        value,
+			CodeSnippet: s.codeSnippet(content, value),
+			Severity:    SeverityLow,
+		})
+		reportedMatches[matchKey] = true
+	}
+
+	metadataHosts := make(map[string]bool)
+	for _, value := range cloudMetadataRegex.FindAllString(content, -1) {
+		metadataHosts[strings.ToLower(value)] = true
+		add(cloudMetadataRuleID, value)
+	}
+
+	for _, loc := range privateIPRegex.FindAllStringIndex(content, -1) {
+		if isPartOfLongerNumber(content, loc[0], loc[1]) {
+			continue
+		}
+		value := content[loc[0]:loc[1]]
+		if net.ParseIP(value) != nil {
+			add(privateIPRuleID, value)
+		}
+	}
+
+	for _, match := range internalHostnameRegex.FindAllStringSubmatch(content, -1) {
+		if !metadataHosts[strings.ToLower(match[1])] {
+			add(internalHostnameRuleID, match[1])
+		}
+	}
+
+	return findings
+}
+
+// isPartOfLongerNumber checks if content[start:end] is surrounded by more
+// digits or dotted components, as in version strings like 1.10.0.0.1
+func isPartOfLongerNumber(content string, start int, end int) bool {
+	isNumeric := func(b byte) bool { return b >= '0' && b <= '9' || b == '.' }
+	return (start > 0 && isNumeric(content[start-1])) || (end < len(content) && isNumeric(content[end]))
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// jwtRuleID is the rule ID reported for JWT findings
+const jwtRuleID = "jsweb-jwt"
+
+// jwtRegex matches three-part tokens whose header and payload are JSON
+// objects (base64url "eyJ" decodes to `{"`)
+var jwtRegex = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*`)
+
+// jwtRule is the pseudo-rule used to apply allowlists to JWT findings
+var jwtRule = config.Rule{
+	ID:          jwtRuleID,
+	Description: "JSON Web Token",
+	Tags:        []string{"jwt"},
+}
+
+// jwtInfo holds the decoded fields of a JWT that are reported. Fields
+// stay empty when the header or payload can't be decoded.
+type jwtInfo struct {
+	Algorithm string
+	Issuer    string
+	Subject   string
+	ExpiresAt time.Time
+	// Undecoded is set when the header or payload isn't valid base64url
+	// JSON
+	Undecoded bool
+}
+
+// Expired reports whether the token has an exp claim in the past
+func (info jwtInfo) Expired() bool {
+	return !info.ExpiresAt.IsZero() && info.ExpiresAt.Before(time.Now())
+}
+
+// String summarizes the token for a finding's context
+func (info jwtInfo) String() string {
+	if info.Undecoded {
+		return "undecodable header or payload"
+	}
+	parts := []string{"alg=" + info.Algorithm}
+	if info.Issuer != "" {
+		parts = append(parts, "iss="+info.Issuer)
+	}
+	if info.Subject != "" {
+		parts = append(parts, "sub="+info.Subject)
+	}
+
+	switch {
+	case info.ExpiresAt.IsZero():
+		parts = append(parts, "exp=none (never expires)")
+	case info.Expired():
+		parts = append(parts, fmt.Sprintf("exp=%s (expired)", info.ExpiresAt.UTC().Format(time.RFC3339)))
+	default:
+		parts = append(parts, fmt.Sprintf("exp=%s (valid)", info.ExpiresAt.UTC().Format(time.RFC3339)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// scanJWTs finds JWTs in content, decodes them, and reports each one with
+// its algorithm, issuer, subject, and expiry. Unexpired tokens get a higher
+// severity than expired ones.
+func (s *Scanner) scanJWTs(file string, content string, reportedMatches map[string]bool) []Finding {
+	var findings []Finding
+
+	for _, token := range jwtRegex.FindAllString(content, -1) {
+		matchKey := fmt.Sprintf("%s:%s:%s", jwtRuleID, file, token)
+		if reportedMatches[matchKey] {
+			continue
+		}
+
+		info := decodeJWT(token)
+		if s.isAllowlisted(token, token, lineContaining(content, token), file, jwtRule) {
+			continue
+		}
+
+		severity := SeverityHigh
+		if info.Expired() {
+			severity = SeverityLow
+		}
+
+		findings = append(findings, Finding{
+			Description: jwtRule.Description,
+			File:        file,
+			RuleID:      jwtRuleID,
+			Tags:        jwtRule.Tags,
+			Secret:      token,
+			Context:     info.String(),
+			Line:        token,
+			Entropy:     calculateEntropy(token),
+			CodeSnippet: s.codeSnippet(content, token),
+			Severity:    severity,
+		})
+		reportedMatches[matchKey] = true
+	}
+
+	return findings
+}
+
+// decodeJWT decodes the header and payload of a JWT. Claims of any JSON
+// type are formatted as text, so an unusual payload only affects what's
+// shown, never whether the token is reported.
+func decodeJWT(token string) jwtInfo {
+	parts := strings.Split(token, ".")
+	var header, claims map[string]json.RawMessage
+	if len(parts) != 3 || !decodeJWTSegment(parts[0], &header) || !decodeJWTSegment(parts[1], &claims) {
+		return jwtInfo{Undecoded: true}
+	}
+
+	info := jwtInfo{
+		Algorithm: jwtClaimString(header["alg"]),
+		Issuer:    jwtClaimString(claims["iss"]),
+		Subject:   jwtClaimString(claims["sub"]),
+	}
+	var exp float64
+	if raw, ok := claims["exp"]; ok && json.Unmarshal(raw, &exp) == nil {
+		info.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	return info
+}
+
+// jwtClaimString formats a claim for display: strings without their
+// quotes, and other JSON values as written
+func jwtClaimString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var str string
+	if json.Unmarshal(raw, &str) == nil {
+		return str
+	}
+	return string(raw)
+}
+
+// decodeJWTSegment decodes a base64url JWT segment into v
+func decodeJWTSegment(segment string, v interface{}) bool {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// makeJWT builds an unsigned-looking token from JSON header and payload
+func makeJWT(header string, payload string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+func TestScanJWTsNumericSubject(t *testing.T) {
+	token := makeJWT(`{"alg":"HS256","typ":"JWT"}`, `{"sub":1234567890,"iss":"https://auth.example.com","exp":4102444800}`)
+	s := New(&config.Config{}, Options{})
+	findings := s.scanJWTs("https://example.com/app.js", `const token = "`+token+`";`, make(map[string]bool))
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	finding := findings[0]
+	if !strings.Contains(finding.Context, "sub=1234567890") || !strings.Contains(finding.Context, "iss=https://auth.example.com") {
+		t.Errorf("context = %q, want the subject and issuer", finding.Context)
+	}
+	if finding.Severity != SeverityHigh {
+		t.Errorf("severity = %q, want %q for an unexpired token", finding.Severity, SeverityHigh)
+	}
+}
+
+func TestDecodeJWTUndecodablePayload(t *testing.T) {
+	token := makeJWT(`{"alg":"HS256"}`, `{"sub":`)
+	info := decodeJWT(token)
+	if !info.Undecoded {
+		t.Errorf("decodeJWT(%q) decoded a truncated payload", token)
+	}
+}
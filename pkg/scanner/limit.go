@@ -0,0 +1,28 @@
+package scanner
+
+// TotalFindings returns the number of findings discovered, which is more
+// than GetFindings returns if findings were dropped beyond MaxFindings
+func (s *Scanner) TotalFindings() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.foundFindings
+}
+
+// Truncated checks if findings were dropped beyond MaxFindings
+func (s *Scanner) Truncated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.foundFindings > len(s.findings)
+}
+
+// limitReached checks if the scan should stop fetching files because
+// MaxFindings was reached and StopOnLimit is set
+func (s *Scanner) limitReached() bool {
+	if !s.opts.StopOnLimit || s.opts.MaxFindings <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.findings) >= s.opts.MaxFindings
+}
@@ -0,0 +1,167 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Login step actions
+const (
+	LoginNavigate = "navigate"
+	LoginFill     = "fill"
+	LoginClick    = "click"
+	LoginWaitFor  = "wait_for"
+)
+
+// defaultLoginTimeout is the per-step timeout when a script doesn't set one
+const defaultLoginTimeout = 30 * time.Second
+
+// LoginStep is a single browser action of a login script
+type LoginStep struct {
+	// Action is one of navigate, fill, click, or wait_for
+	Action string `json:"action"`
+	// URL is the page to open (navigate) or a URL glob to wait for (wait_for)
+	URL string `json:"url,omitempty"`
+	// Selector is the element to fill, click, or wait for
+	Selector string `json:"selector,omitempty"`
+	// Value is the text to fill; $VAR and ${VAR} expand from the environment
+	Value string `json:"value,omitempty"`
+}
+
+// LoginCheck is a condition confirming a login succeeded
+type LoginCheck struct {
+	// Selector must become visible on the page
+	Selector string `json:"selector,omitempty"`
+	// URL is a glob the page URL must match
+	URL string `json:"url,omitempty"`
+}
+
+// LoginScript describes the steps to log in before scanning
+type LoginScript struct {
+	Steps   []LoginStep `json:"steps"`
+	Success LoginCheck  `json:"success"`
+	// Timeout is the per-step timeout in seconds (default 30)
+	Timeout float64 `json:"timeout,omitempty"`
+}
+
+// LoadLoginScript reads and validates a JSON login script
+func LoadLoginScript(path string) (*LoginScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login script: %v", err)
+	}
+
+	var script LoginScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse login script: %v", err)
+	}
+
+	if len(script.Steps) == 0 {
+		return nil, fmt.Errorf("login script has no steps")
+	}
+	for i, step := range script.Steps {
+		switch step.Action {
+		case LoginNavigate:
+			if step.URL == "" {
+				return nil, fmt.Errorf("login step %d: navigate requires a url", i+1)
+			}
+		case LoginFill, LoginClick:
+			if step.Selector == "" {
+				return nil, fmt.Errorf("login step %d: %s requires a selector", i+1, step.Action)
+			}
+		case LoginWaitFor:
+			if step.Selector == "" && step.URL == "" {
+				return nil, fmt.Errorf("login step %d: wait_for requires a selector or url", i+1)
+			}
+		default:
+			return nil, fmt.Errorf("login step %d: unknown action %q", i+1, step.Action)
+		}
+	}
+
+	return &script, nil
+}
+
+// login runs the login script in a new page and adds the resulting session
+// cookies to the domain cookies used for every page and file fetch
+func (s *Scanner) login(openPage pageOpener, pageURL string) error {
+	script := s.opts.LoginScript
+
+	page, err := s.newPage(openPage, pageURL)
+	if err != nil {
+		return err
+	}
+	defer page.Close()
+	defer s.startTrace(page, pageURL)()
+
+	timeout := defaultLoginTimeout
+	if script.Timeout > 0 {
+		timeout = time.Duration(script.Timeout * float64(time.Second))
+	}
+	page.SetDefaultTimeout(float64(timeout.Milliseconds()))
+	page.SetDefaultNavigationTimeout(float64(timeout.Milliseconds()))
+
+	for i, step := range script.Steps {
+		s.debugf("Login step %d: %s", i+1, step.Action)
+		if err := runLoginStep(page, step); err != nil {
+			return fmt.Errorf("login step %d (%s) failed: %v", i+1, step.Action, err)
+		}
+	}
+
+	if script.Success.Selector != "" || script.Success.URL != "" {
+		check := LoginStep{Action: LoginWaitFor, Selector: script.Success.Selector, URL: script.Success.URL}
+		if err := runLoginStep(page, check); err != nil {
+			return fmt.Errorf("login did not succeed: %v", err)
+		}
+	}
+
+	cookies, err := page.Context().Cookies()
+	if err != nil {
+		return fmt.Errorf("failed to read login cookies: %v", err)
+	}
+	for _, cookie := range cookies {
+		sessionCookie := &http.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		}
+		if cookie.Expires > 0 {
+			sessionCookie.Expires = time.Unix(int64(cookie.Expires), 0)
+		}
+		s.opts.DomainCookies = append(s.opts.DomainCookies, sessionCookie)
+	}
+	s.debugf("Logged in with %d session cookies", len(cookies))
+
+	return nil
+}
+
+// runLoginStep performs a single login step on page
+func runLoginStep(page playwright.Page, step LoginStep) error {
+	switch step.Action {
+	case LoginNavigate:
+		_, err := page.Goto(step.URL)
+		return err
+	case LoginFill:
+		return page.Locator(step.Selector).Fill(os.ExpandEnv(step.Value))
+	case LoginClick:
+		return page.Locator(step.Selector).Click()
+	case LoginWaitFor:
+		if step.URL != "" {
+			if err := page.WaitForURL(step.URL); err != nil {
+				return err
+			}
+		}
+		if step.Selector != "" {
+			return page.Locator(step.Selector).WaitFor()
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", step.Action)
+}
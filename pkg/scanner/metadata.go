@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"time"
+)
+
+// ReportMetadata records how a report was produced, so it can be
+// reproduced: what was scanned, with which build and rules, and the
+// options that change what's detected
+type ReportMetadata struct {
+	Targets    []string `json:"targets,omitempty"`
+	Version    string   `json:"version,omitempty"`
+	Commit     string   `json:"commit,omitempty"`
+	ConfigHash string   `json:"config_hash,omitempty"`
+	Timestamp  string   `json:"timestamp,omitempty"`
+	// Parameters are the detection options set away from their defaults,
+	// keyed by option name
+	Parameters      map[string]interface{} `json:"parameters,omitempty"`
+	FilesScanned    int                    `json:"files_scanned"`
+	DurationSeconds float64                `json:"duration_seconds"`
+}
+
+// reportMetadata returns the metadata of a report on the scans run so far
+func (s *Scanner) reportMetadata(info ReportInfo) *ReportMetadata {
+	stats := s.Stats()
+	metadata := &ReportMetadata{
+		Targets:         info.Targets,
+		Version:         info.Version,
+		Commit:          info.Commit,
+		ConfigHash:      info.ConfigHash,
+		Parameters:      s.detectionParameters(),
+		FilesScanned:    stats.FilesScanned,
+		DurationSeconds: stats.Duration.Round(time.Millisecond).Seconds(),
+	}
+	if !info.Timestamp.IsZero() {
+		metadata.Timestamp = info.Timestamp.UTC().Format(time.RFC3339)
+	}
+	return metadata
+}
+
+// detectionParameters returns the options that change which findings are
+// reported, leaving out those at their defaults
+func (s *Scanner) detectionParameters() map[string]interface{} {
+	params := make(map[string]interface{})
+	set := func(name string, value interface{}, isSet bool) {
+		if isSet {
+			params[name] = value
+		}
+	}
+
+	o := s.opts
+	if s.config != nil {
+		set("disabled_rules", s.config.Extend.DisabledRules, len(s.config.Extend.DisabledRules) > 0)
+	}
+	set("entropy_mode", o.EntropyMode, o.EntropyMode != "")
+	set("max_entropy", o.MaxEntropy, o.MaxEntropy > 0)
+	set("entropy_zscore", s.entropyZScore(), o.DifferentialEntropy)
+	set("min_secret_length", o.MinSecretLength, o.MinSecretLength > 0)
+	set("keyword_case_sensitive", o.KeywordCaseSensitive, o.KeywordCaseSensitive)
+	set("keyword_word_boundary", o.KeywordWordBoundary, o.KeywordWordBoundary)
+	set("min_severity", o.MinSeverity, o.MinSeverity != "")
+	set("include_tags", o.IncludeTags, len(o.IncludeTags) > 0)
+	set("exclude_tags", o.ExcludeTags, len(o.ExcludeTags) > 0)
+	set("ignore_files", o.IgnoreFiles, len(o.IgnoreFiles) > 0)
+	set("only_files", o.OnlyFiles, len(o.OnlyFiles) > 0)
+	set("max_file_size", o.MaxFileSize, o.MaxFileSize > 0)
+	set("max_findings", o.MaxFindings, o.MaxFindings > 0)
+	set("sample_rate", o.SampleRate, o.SampleRate > 0 && o.SampleRate < 1)
+	set("prefer_specific", o.PreferSpecific, o.PreferSpecific)
+	set("normalize_secrets", o.NormalizeSecrets, o.NormalizeSecrets)
+	set("strict_validation", o.StrictValidation, o.StrictValidation)
+	set("filter_uuids", o.FilterUUIDs, o.FilterUUIDs)
+	set("filter_hashes", o.FilterHashes, o.FilterHashes)
+	set("downgrade_comments", o.DowngradeComments, o.DowngradeComments)
+	set("comments_only", o.CommentsOnly, o.CommentsOnly)
+	set("disable_jwt", o.DisableJWT, o.DisableJWT)
+	set("disable_private_key", o.DisablePrivateKey, o.DisablePrivateKey)
+	set("scan_json", o.ScanJSON, o.ScanJSON)
+	set("scan_wasm", o.ScanWasm, o.ScanWasm)
+	set("scan_html", o.ScanHTML, o.ScanHTML)
+	set("scan_attributes", o.ScanAttributes, o.ScanAttributes)
+	set("scan_storage", o.ScanStorage, o.ScanStorage)
+	set("scan_objects", o.ScanObjects, o.ScanObjects)
+	set("scan_query_params", o.ScanQueryParams, o.ScanQueryParams)
+	set("runtime_scan", o.RuntimeScan, o.RuntimeScan)
+	set("detect_internal", o.DetectInternal, o.DetectInternal)
+	set("chunk_depth", o.ChunkDepth, o.ChunkDepth > 0)
+	set("no_browser", o.NoBrowser, o.NoBrowser)
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
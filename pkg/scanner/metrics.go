@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MetricsInfo describes the run that metrics are written for
+type MetricsInfo struct {
+	// Host labels every metric with the scanned target host
+	Host string
+}
+
+// WriteMetrics writes Prometheus text-format metrics for the run to w
+func (s *Scanner) WriteMetrics(w io.Writer, info MetricsInfo) error {
+	host := escapeLabelValue(info.Host)
+
+	ruleCounts := make(map[string]int)
+	for _, finding := range s.GetFindings() {
+		ruleCounts[finding.RuleID]++
+	}
+	ruleIDs := make([]string, 0, len(ruleCounts))
+	for ruleID := range ruleCounts {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+
+	stats := s.Stats()
+
+	var b strings.Builder
+	b.WriteString("# HELP jsweb_findings_total Findings reported by the last scan.\n")
+	b.WriteString("# TYPE jsweb_findings_total gauge\n")
+	for _, ruleID := range ruleIDs {
+		fmt.Fprintf(&b, "jsweb_findings_total{host=\"%s\",rule_id=\"%s\"} %d\n", host, escapeLabelValue(ruleID), ruleCounts[ruleID])
+	}
+	b.WriteString("# HELP jsweb_files_scanned_total Files scanned by the last scan.\n")
+	b.WriteString("# TYPE jsweb_files_scanned_total gauge\n")
+	fmt.Fprintf(&b, "jsweb_files_scanned_total{host=\"%s\"} %d\n", host, stats.FilesScanned)
+	b.WriteString("# HELP jsweb_scan_duration_seconds Duration of the last scan.\n")
+	b.WriteString("# TYPE jsweb_scan_duration_seconds gauge\n")
+	fmt.Fprintf(&b, "jsweb_scan_duration_seconds{host=\"%s\"} %g\n", host, stats.Duration.Seconds())
+	b.WriteString("# HELP jsweb_errors_total Files that failed to fetch, plus the scan itself if it failed.\n")
+	b.WriteString("# TYPE jsweb_errors_total gauge\n")
+	fmt.Fprintf(&b, "jsweb_errors_total{host=\"%s\"} %d\n", host, stats.Errors)
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write metrics: %v", err)
+	}
+	return nil
+}
+
+// WriteMetricsFile writes metrics for the run to path. The file is replaced
+// atomically so a scrape never reads a half-written file.
+func (s *Scanner) WriteMetricsFile(path string, info MetricsInfo) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := s.WriteMetrics(tmp, info); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set metrics file permissions: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace metrics file: %v", err)
+	}
+	return nil
+}
+
+// escapeLabelValue escapes a Prometheus label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// fetchPageScripts fetches pageURL over HTTP and returns the JavaScript
+// files referenced by its script tags, for NoBrowser scans. Scripts added
+// by the page's own JavaScript aren't found. The page is fetched within ctx
+// and shares the scan's rate limit.
+func (s *Scanner) fetchPageScripts(ctx context.Context, pageURL string) ([]string, error) {
+	resp, err := s.fetch(ctx, pageURL, "page "+pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%w %s: status %d", ErrFetch, pageURL, resp.StatusCode)
+	}
+
+	// Read one byte past the limit, so the HTML of an oversized page is
+	// skipped by checkPageHTML rather than scanned truncated
+	maxSize := s.opts.MaxFileSize
+	var body io.Reader = resp.Body
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrFetch, pageURL, err)
+	}
+	if maxSize > 0 && int64(len(content)) > maxSize {
+		fmt.Fprintf(os.Stderr, "Warning: %s exceeds the size limit of %d bytes, only finding scripts in its start\n", pageURL, maxSize)
+	}
+	doc, err := html.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page %s: %v", pageURL, err)
+	}
+
+	// Resolve srcs against the final URL after redirects, or the page's
+	// <base href> if it has one
+	base, _ := url.Parse(pageURL)
+	if resp.Request != nil && resp.Request.URL != nil {
+		base = resp.Request.URL
+	}
+	finalURL := base.String()
+	var sources []string
+	var baseHref string
+	var attributes []pageAttribute
+	var metaPolicies []string
+	var scriptTags []scriptTag
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if s.opts.ScanAttributes {
+				attributes = append(attributes, nodeAttributes(base.String(), n)...)
+			}
+			switch n.Data {
+			case "script":
+				if src, ok := htmlAttr(n, "src"); ok && strings.TrimSpace(src) != "" {
+					sources = append(sources, src)
+					if s.opts.CheckSRI {
+						integrity, _ := htmlAttr(n, "integrity")
+						crossOrigin, hasCrossOrigin := htmlAttr(n, "crossorigin")
+						scriptTags = append(scriptTags, scriptTag{Src: strings.TrimSpace(src), Integrity: integrity, CrossOrigin: crossOrigin, HasCrossOrigin: hasCrossOrigin})
+					}
+				}
+				if kind, _ := htmlAttr(n, "type"); strings.EqualFold(strings.TrimSpace(kind), "importmap") {
+					sources = append(sources, importMapURLs(nodeText(n))...)
+				}
+			case "meta":
+				if equiv, _ := htmlAttr(n, "http-equiv"); s.opts.ReportCSP && strings.EqualFold(strings.TrimSpace(equiv), "content-security-policy") {
+					policy, _ := htmlAttr(n, "content")
+					metaPolicies = append(metaPolicies, policy)
+				}
+			case "base":
+				if href, ok := htmlAttr(n, "href"); ok && baseHref == "" {
+					baseHref = href
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(doc)
+	s.addAttributes(attributes)
+	if s.opts.ScanHTML {
+		s.addPageHTML(finalURL, resp.Header.Get("Content-Type"), content)
+	}
+	if s.opts.ReportCSP {
+		s.addCSP(finalURL, append(splitCSPHeader(resp.Header.Values("Content-Security-Policy")), metaPolicies...))
+	}
+
+	if baseHref != "" {
+		if ref, err := url.Parse(strings.TrimSpace(baseHref)); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+	if s.opts.CheckSRI {
+		for i, tag := range scriptTags {
+			if ref, err := url.Parse(tag.Src); err == nil {
+				scriptTags[i].Src = base.ResolveReference(ref).String()
+			}
+		}
+		s.addScriptTags(finalURL, scriptTags)
+	}
+
+	return s.collectJSFiles(finalURL, base, sources), nil
+}
+
+// nodeText returns the text content of an element
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			b.WriteString(child.Data)
+		}
+	}
+	return b.String()
+}
+
+// htmlAttr returns the value of an element's attribute
+func htmlAttr(n *html.Node, name string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Namespace == "" && strings.EqualFold(attr.Key, name) {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,231 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// objectSecretRuleID is the rule ID reported for values under credential
+// keys of object literals
+const objectSecretRuleID = "jsweb-object-secret"
+
+// objectSecretRule is the pseudo-rule used to apply allowlists to object
+// literal findings
+var objectSecretRule = config.Rule{
+	ID:          objectSecretRuleID,
+	Description: "Credential-named property in an object literal",
+	Tags:        []string{"object-key"},
+}
+
+// minObjectSecretLength is the shortest value reported under a credential
+// key, which skips labels and flags such as "Bearer" or "on"
+const minObjectSecretLength = 8
+
+// wordLikeRegex matches values made of letters only, such as header names
+// or labels ("Authorization", "client_secret"), that aren't credentials
+var wordLikeRegex = regexp.MustCompile(`^[A-Za-z]+(?:[ _.-][A-Za-z]+)*$`)
+
+// objectProperty is a string-valued property of an object literal
+type objectProperty struct {
+	// Path is the dotted key path from the outermost object literal, such
+	// as firebase.apiKey
+	Path  string
+	Key   string
+	Value string
+	// Source is the property as it appears in content
+	Source string
+}
+
+// scanObjects reports the string values of object literal properties with
+// credential-like keys (apiKey, clientSecret, token, ...), whatever their
+// entropy, for configuration objects such as {firebase:{apiKey:"..."}}
+// inlined in bundles. Secrets already reported by a rule aren't repeated.
+// Findings carry the key path as their context.
+func (s *Scanner) scanObjects(file string, content string, reportedMatches map[string]bool, reported []Finding) []Finding {
+	known := make(map[string]bool)
+	for _, finding := range reported {
+		known[finding.Secret] = true
+	}
+
+	var findings []Finding
+	for _, property := range extractObjectProperties(content) {
+		value := strings.TrimSpace(property.Value)
+		if !isCredentialIdentifier(property.Key) || !isObjectSecret(value) || known[value] {
+			continue
+		}
+		matchKey := fmt.Sprintf("%s:%s:%s", objectSecretRuleID, file, value)
+		if reportedMatches[matchKey] {
+			continue
+		}
+		if s.isAllowlisted(property.Source, value, lineContaining(content, property.Source), file, objectSecretRule) {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Description:       objectSecretRule.Description,
+			File:              file,
+			RuleID:            objectSecretRuleID,
+			Tags:              objectSecretRule.Tags,
+			Secret:            value,
+			Context:           property.Path,
+			Line:              property.Source,
+			Entropy:           calculateEntropy(value),
+			CodeSnippet:       s.codeSnippet(content, property.Source),
+			Severity:          SeverityMedium,
+			ContextIdentifier: property.Key,
+			ContextConfidence: contextConfidenceDirect,
+		})
+		reportedMatches[matchKey] = true
+	}
+	return findings
+}
+
+// isObjectSecret checks if a value under a credential key could be a
+// credential rather than a label, URL, or path
+func isObjectSecret(value string) bool {
+	return len(value) >= minObjectSecretLength &&
+		!strings.ContainsAny(value, " \t\n") &&
+		!strings.Contains(value, "://") &&
+		!strings.HasPrefix(value, "/") &&
+		!wordLikeRegex.MatchString(value)
+}
+
+// extractObjectProperties leniently walks the object and array literals of
+// content, returning every property whose value is a string literal with
+// its key path. Keys are identifiers or string literals following "{" or
+// ","; braces of blocks and functions nest like objects with no key, so
+// they don't appear in paths. Comments, strings, and regex literals are
+// skipped.
+func extractObjectProperties(content string) []objectProperty {
+	var properties []objectProperty
+	var stack []string
+
+	// prev is the kind of the previous token: '{' after an opening
+	// bracket, 'k' after a possible key, 'v' after any other value, and
+	// the character itself after other punctuation
+	prev := byte(';')
+	key, keyStart, keyOK := "", 0, false
+	property, propertyStart, afterColon := "", 0, false
+
+	path := func(last string) string {
+		var parts []string
+		for _, part := range stack {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(append(parts, last), ".")
+	}
+	// token records a key candidate, or the value of the current property
+	token := func(start int, end int, text string, isString bool) {
+		if afterColon {
+			if isString {
+				properties = append(properties, objectProperty{
+					Path:   path(property),
+					Key:    property,
+					Value:  text,
+					Source: content[propertyStart:end],
+				})
+			}
+			afterColon = false
+			prev = 'v'
+			return
+		}
+		key, keyStart, keyOK = text, start, prev == '{' || prev == ','
+		prev = 'k'
+	}
+
+	for i := 0; i < len(content); i++ {
+		if end := commentEnd(content, i); end >= 0 {
+			i = end - 1
+			continue
+		}
+
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == '/' && startsRegex(prev):
+			i = regexEnd(content, i) - 1
+			afterColon = false
+			prev = 'v'
+		case c == '"' || c == '\'' || c == '`':
+			end := quotedEnd(content, i, c)
+			token(i, end, unquoteJSString(content[i:end]), true)
+			i = end - 1
+		case isIdentifierStart(c):
+			end := i + 1
+			for end < len(content) && isIdentifierPart(content[end]) {
+				end++
+			}
+			token(i, end, content[i:end], false)
+			i = end - 1
+		case c == ':':
+			afterColon = prev == 'k' && keyOK
+			property, propertyStart = key, keyStart
+			prev = ':'
+		case c == '{' || c == '[':
+			frame := ""
+			if afterColon {
+				frame = property
+			}
+			stack = append(stack, frame)
+			afterColon = false
+			prev = '{'
+		case c == '}' || c == ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			afterColon = false
+			prev = 'v'
+		case c == ',':
+			afterColon = false
+			prev = ','
+		default:
+			afterColon = false
+			prev = c
+		}
+	}
+	return properties
+}
+
+// quotedEnd returns the offset just past the string literal opened at
+// start, or, when it isn't closed, the newline ending a quoted string or
+// the end of content
+func quotedEnd(content string, start int, quote byte) int {
+	end := skipQuoted(content, start, quote)
+	if end < len(content) && content[end] == '\n' && quote != '`' {
+		return end
+	}
+	return end + 1
+}
+
+// regexEnd returns the offset just past the regex literal opened at start,
+// including its flags, or the newline ending it if it isn't closed
+func regexEnd(content string, start int) int {
+	end := skipRegex(content, start)
+	if content[end] == '\n' {
+		return end
+	}
+	if content[end] != '/' || end == start {
+		return end + 1
+	}
+	end++
+	for end < len(content) && isIdentifierPart(content[end]) {
+		end++
+	}
+	return end
+}
+
+// isIdentifierStart checks if c can start a JavaScript identifier
+func isIdentifierStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentifierPart checks if c can continue a JavaScript identifier. Digits
+// are included so numbers are read as one token.
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractObjectProperties(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+	}{
+		{
+			name:    "nested key paths",
+			content: `var config = {firebase: {apiKey: "AIzaSyA1b2C3d4E5f6G7h8", "authDomain": 'app.firebaseapp.com'}, debug: false};`,
+			want:    map[string]string{"firebase.apiKey": "AIzaSyA1b2C3d4E5f6G7h8", "firebase.authDomain": "app.firebaseapp.com"},
+		},
+		{
+			name:    "arrays and blocks add no path",
+			content: `function init() { return {keys: [{token: "t0k3n-value"}]}; }`,
+			want:    map[string]string{"keys.token": "t0k3n-value"},
+		},
+		{
+			name: "comments skipped",
+			content: `var a = {
+				// secret: "in-line-comment",
+				/* password: "in-block-comment", */
+				clientId: "abc123"
+			};`,
+			want: map[string]string{"clientId": "abc123"},
+		},
+		{
+			name:    "regex literals skipped",
+			content: `var re = /{apiKey: "x"}/g, b = {token: "after-regex"};`,
+			want:    map[string]string{"token": "after-regex"},
+		},
+		{
+			name:    "strings containing braces skipped",
+			content: `var s = "{apiKey: 'in-string'}", t = {secret: "it's \"quoted\""};`,
+			want:    map[string]string{"secret": `it's "quoted"`},
+		},
+		{
+			name:    "division isn't a regex",
+			content: `var x = a / 2, y = {token: "after-division"} / 1;`,
+			want:    map[string]string{"token": "after-division"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			for _, property := range extractObjectProperties(tt.content) {
+				got[property.Path] = property.Value
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractObjectProperties() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ReportInfo describes the scan a report was produced from and how to
+// write it
+type ReportInfo struct {
+	Targets []string
+	Version string
+	// Commit is the source commit jsweb was built from
+	Commit    string
+	Timestamp time.Time
+	// ConfigHash is the hash of the configuration the rules came from (see
+	// config.Config.Hash), so findings that change between runs can be
+	// traced to rule changes
+	ConfigHash string
+	// Compact writes JSON on a single line instead of indented
+	Compact bool
+	// Color colorizes the text format with ANSI escape sequences
+	Color bool
+	// Template renders the TemplateFormat, executed with TemplateData
+	Template *template.Template
+	// Fields limits the findings of JSON and JSONL reports to the named
+	// fields, in that order (see FindingFields)
+	Fields []string
+}
+
+// Formats lists the supported output formats
+var Formats = []string{"json", "jsonl", "html", "text", GitLabFormat}
+
+// IsValidFormat checks if format is a supported output format
+func IsValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintFindings prints all findings in JSON format
+func (s *Scanner) PrintFindings() error {
+	return s.WriteReport(os.Stdout, "json", ReportInfo{})
+}
+
+// reportData holds everything included in a report
+type reportData struct {
+	Findings  []Finding
+	Skipped   []FileReport
+	Endpoints []Endpoint
+	// Files lists every file with its captured headers when
+	// Options.CaptureHeaders is set
+	Files []FileReport
+	// TotalFindings is the number of findings discovered when Findings was
+	// truncated to MaxFindings, and 0 otherwise
+	TotalFindings int
+	// Sample describes the sample of files checked with SampleRate, and is
+	// nil for full scans
+	Sample *SampleInfo
+	// Partial describes how far a scan stopped by its deadline got, and is
+	// nil for complete scans
+	Partial *PartialInfo
+	// Metadata describes how the report was produced, for the json and
+	// jsonl formats
+	Metadata *ReportMetadata
+}
+
+// WriteReport writes all findings to w in the given format ("json", "jsonl",
+// "html", "text", or "gitlab")
+func (s *Scanner) WriteReport(w io.Writer, format string, info ReportInfo) error {
+	data := reportData{Findings: s.SortedFindings()}
+	if s.opts.QuietOnClean && len(data.Findings) == 0 {
+		return nil
+	}
+	if s.opts.ReportSkipped {
+		data.Skipped = s.GetReport().Skipped()
+	}
+	if s.opts.ExtractEndpoints {
+		data.Endpoints = s.GetEndpoints()
+	}
+	if s.opts.CaptureHeaders {
+		data.Files = s.GetReport().Files
+	}
+	if s.Truncated() {
+		data.TotalFindings = s.TotalFindings()
+	}
+	data.Sample = s.Sample()
+	data.Partial = s.Partial()
+	data.Metadata = s.reportMetadata(info)
+
+	return writeReport(w, format, data, info)
+}
+
+// writeReport writes data to w in the given format
+func writeReport(w io.Writer, format string, data reportData, info ReportInfo) error {
+	switch format {
+	case "", "json":
+		return writeJSON(w, data, info)
+	case "jsonl":
+		return writeJSONL(w, data, info.Fields)
+	case "html":
+		return writeHTML(w, data, info)
+	case "text":
+		return writeText(w, data, info)
+	case GitLabFormat:
+		return writeGitLab(w, data.Findings, info)
+	case TemplateFormat:
+		return writeTemplate(w, data, info)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// FormatExtension returns the file extension, without a dot, of reports in
+// format
+func FormatExtension(format string) string {
+	switch format {
+	case "", GitLabFormat:
+		return "json"
+	case "text", TemplateFormat:
+		return "txt"
+	}
+	return format
+}
+
+// WriteHostReports writes one report per target host into dir, named by
+// the sanitized host with an extension for the format. Each report holds
+// the findings of targets on that host. It returns the paths written.
+func (s *Scanner) WriteHostReports(dir string, format string, info ReportInfo) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	byHost := make(map[string][]Finding)
+	var hosts []string
+	for _, finding := range s.SortedFindings() {
+		host := TargetHost(finding.Target)
+		if host == "" {
+			host = "unknown"
+		}
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], finding)
+	}
+	sort.Strings(hosts)
+
+	// Report files are never colorized
+	info.Color = false
+
+	ext := FormatExtension(format)
+
+	// Every report is marked truncated with the scan's total, since the
+	// dropped findings' hosts aren't known
+	totalFindings := 0
+	if s.Truncated() {
+		totalFindings = s.TotalFindings()
+	}
+
+	metadata := s.reportMetadata(info)
+
+	var paths []string
+	for _, host := range hosts {
+		path := filepath.Join(dir, sanitizeFileName(host)+"."+ext)
+		data := reportData{Findings: byHost[host], TotalFindings: totalFindings, Sample: s.Sample(), Partial: s.Partial(), Metadata: metadata}
+		if err := writeReportFile(path, format, data, info); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// writeReportFile writes a report for data to path
+func writeReportFile(path string, format string, data reportData, info ReportInfo) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %v", err)
+	}
+
+	err = writeReport(file, format, data, info)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write report file: %v", closeErr)
+	}
+	return err
+}
+
+// SortedFindings returns a copy of the findings in report order: sorted by
+// severity, then triage score, in descending order
+func (s *Scanner) SortedFindings() []Finding {
+	s.mu.Lock()
+	findings := append([]Finding{}, s.findings...)
+	s.mu.Unlock()
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if ri, rj := severityRank(findings[i].Severity), severityRank(findings[j].Severity); ri != rj {
+			return ri > rj
+		}
+		return triageScore(findings[i]) > triageScore(findings[j])
+	})
+	return findings
+}
+
+// writeJSON writes findings (and the report's metadata, and any skipped
+// files, endpoints, and file headers) as a JSON document
+func writeJSON(w io.Writer, data reportData, info ReportInfo) error {
+	findings, err := projectFindings(data.Findings, info.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings: %v", err)
+	}
+	output := struct {
+		Metadata      *ReportMetadata `json:"metadata,omitempty"`
+		ConfigHash    string          `json:"config_hash,omitempty"`
+		Findings      interface{}     `json:"findings"`
+		Truncated     bool            `json:"truncated,omitempty"`
+		TotalFindings int             `json:"total_findings,omitempty"`
+		Sample        *SampleInfo     `json:"sample,omitempty"`
+		Partial       bool            `json:"partial,omitempty"`
+		Progress      *PartialInfo    `json:"progress,omitempty"`
+		Skipped       []FileReport    `json:"skipped,omitempty"`
+		Endpoints     []Endpoint      `json:"endpoints,omitempty"`
+		Files         []FileReport    `json:"files,omitempty"`
+	}{
+		Metadata:      data.Metadata,
+		ConfigHash:    info.ConfigHash,
+		Findings:      findings,
+		Truncated:     data.TotalFindings > 0,
+		TotalFindings: data.TotalFindings,
+		Sample:        data.Sample,
+		Partial:       data.Partial != nil,
+		Progress:      data.Partial,
+		Skipped:       data.Skipped,
+		Endpoints:     data.Endpoints,
+		Files:         data.Files,
+	}
+
+	jsonData, err := marshalJSON(output, info.Compact)
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings: %v", err)
+	}
+
+	// Ensure we add a trailing newline
+	if _, err := fmt.Fprintln(w, string(jsonData)); err != nil {
+		return fmt.Errorf("failed to write findings: %v", err)
+	}
+
+	return nil
+}
+
+// writeJSONL writes a header record holding the report's metadata, then
+// one finding per line, limited to fields if any are given. Skipped files
+// and endpoints aren't included.
+func writeJSONL(w io.Writer, data reportData, fields []string) error {
+	if data.Metadata != nil {
+		header := struct {
+			Metadata *ReportMetadata `json:"metadata"`
+		}{data.Metadata}
+		if err := writeJSONLine(w, header); err != nil {
+			return fmt.Errorf("failed to write report metadata: %v", err)
+		}
+	}
+	for _, finding := range data.Findings {
+		if err := WriteFindingJSONL(w, finding, fields...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFindingJSONL writes a finding to w as a single JSON line, the format
+// used for each finding by the jsonl output format, limited to fields if
+// any are given
+func WriteFindingJSONL(w io.Writer, finding Finding, fields ...string) error {
+	projected, err := projectFinding(finding, fields)
+	if err != nil {
+		return fmt.Errorf("failed to write finding: %v", err)
+	}
+	if err := writeJSONLine(w, projected); err != nil {
+		return fmt.Errorf("failed to write finding: %v", err)
+	}
+	return nil
+}
+
+// writeJSONLine writes v to w as compact JSON followed by a newline in a
+// single write
+func writeJSONLine(w io.Writer, v interface{}) error {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(jsonData, '\n'))
+	return err
+}
+
+// marshalJSON encodes v indented with two spaces, or on a single line if
+// compact is set
+func marshalJSON(v interface{}, compact bool) ([]byte, error) {
+	if compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// redactSecret masks all but the first few characters of a secret
+func redactSecret(secret string) string {
+	runes := []rune(secret)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:4]) + strings.Repeat("*", len(runes)-4)
+}
@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+)
+
+// pageHTML is the HTML of a page queued for ScanHTML
+type pageHTML struct {
+	URL         string
+	ContentType string
+	Content     []byte
+}
+
+// addPageHTML queues the HTML of pageURL to be scanned with its files
+func (s *Scanner) addPageHTML(pageURL string, contentType string, content []byte) {
+	if len(content) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pendingHTML = append(s.pendingHTML, pageHTML{URL: pageURL, ContentType: contentType, Content: content})
+}
+
+// checkPageHTML scans the queued page HTML for secrets, such as keys in
+// meta tags or inline state, attributing findings to target
+func (s *Scanner) checkPageHTML(target string) {
+	s.mu.Lock()
+	pages := s.pendingHTML
+	s.pendingHTML = nil
+	s.mu.Unlock()
+
+	for _, page := range pages {
+		if s.limitReached() {
+			return
+		}
+		if maxSize := s.opts.MaxFileSize; maxSize > 0 && int64(len(page.Content)) > maxSize {
+			fmt.Fprintf(os.Stderr, "Warning: Skipping the HTML of %s: size exceeds limit of %d bytes\n", page.URL, maxSize)
+			continue
+		}
+		s.debugf("Scanning the HTML of %s", page.URL)
+		s.checkContent(page.URL, target, page.ContentType, page.Content)
+	}
+}
@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PartialInfo describes how far a scan got before it was stopped, such as
+// by a deadline
+type PartialInfo struct {
+	FilesScanned int `json:"files_scanned"`
+	// FilesRemaining counts the files found but not checked, including
+	// those whose fetch was cut short. Files of pages never visited aren't
+	// known, so they're counted in PagesRemaining instead.
+	FilesRemaining int `json:"files_remaining"`
+	PagesRemaining int `json:"pages_remaining"`
+}
+
+// String formats the progress as a one-line note
+func (p PartialInfo) String() string {
+	return fmt.Sprintf("partial scan: %d %s scanned, %d %s and %d %s remaining",
+		p.FilesScanned, plural(p.FilesScanned, "file"),
+		p.FilesRemaining, plural(p.FilesRemaining, "file"),
+		p.PagesRemaining, plural(p.PagesRemaining, "page"))
+}
+
+// addUnchecked counts files and pages left unchecked when the scan's
+// context was cancelled
+func (s *Scanner) addUnchecked(files int, pages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uncheckedFiles += files
+	s.uncheckedPages += pages
+}
+
+// isStopped checks if err means a scan was cancelled or ran past its
+// deadline
+func isStopped(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Partial returns how far the scan got if a run was stopped before it
+// finished, or nil if every run completed
+func (s *Scanner) Partial() *PartialInfo {
+	stats := s.Stats()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.stopped {
+		return nil
+	}
+	return &PartialInfo{
+		FilesScanned:   stats.FilesScanned,
+		FilesRemaining: s.uncheckedFiles,
+		PagesRemaining: s.uncheckedPages,
+	}
+}
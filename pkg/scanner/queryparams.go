@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// urlParamTag marks findings from the query string of a URL in the content
+const urlParamTag = "url-param"
+
+// scanQueryParams runs the detection rules over the decoded query
+// parameters of URLs referenced in content, as "name=value" text, so keys
+// passed as parameters are found even when the rules expect a quoted
+// literal. Findings are tagged "url-param" and carry the URL as context.
+func (s *Scanner) scanQueryParams(file string, content string, reportedMatches map[string]bool) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+
+	for _, rawURL := range queryURLs(content) {
+		if seen[rawURL] {
+			continue
+		}
+		seen[rawURL] = true
+
+		// Keep whatever parsed before a malformed parameter
+		query := rawURL[strings.Index(rawURL, "?")+1:]
+		if i := strings.Index(query, "#"); i >= 0 {
+			query = query[:i]
+		}
+		params, _ := url.ParseQuery(query)
+
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			for _, value := range params[name] {
+				if value == "" {
+					continue
+				}
+				for _, finding := range s.scanContent(file, name+"="+value, reportedMatches) {
+					finding.Tags = append(append([]string{}, finding.Tags...), urlParamTag)
+					finding.Context = rawURL
+					finding.CodeSnippet = s.codeSnippet(content, rawURL)
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+// queryURLs returns the absolute URLs and root-relative paths in string
+// literals of content that have a query string
+func queryURLs(content string) []string {
+	var urls []string
+	for _, re := range []*regexp.Regexp{endpointURLRegex, endpointPathRegex} {
+		for _, match := range re.FindAllStringSubmatch(content, -1) {
+			if strings.Contains(match[1], "?") {
+				urls = append(urls, match[1])
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,192 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxRateLimitRetries is how many times a file answered with 429 Too Many
+// Requests is fetched again before it's skipped
+const maxRateLimitRetries = 3
+
+// maxRetryAfter caps the wait taken from a Retry-After header, so a server
+// can't stall the scan indefinitely
+const maxRetryAfter = time.Minute
+
+// The rates, in requests per second, the scan slows to when it's rate
+// limited: backoffRate on the first 429 when no Rate is set, halving on
+// each later one down to minBackoffRate
+const (
+	backoffRate    = 5
+	minBackoffRate = 0.2
+)
+
+// minBackoffInterval is the shortest time between two slowdowns, for
+// servers answering 429 without a Retry-After
+const minBackoffInterval = time.Second
+
+// recoverAfter is how many fetches in a row must not be rate limited
+// before the rate is doubled back toward the configured one
+const recoverAfter = 20
+
+// retryAfter returns the wait a Retry-After header asks for, given in
+// seconds or as an HTTP date, or fallback if it's missing or invalid
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	header = strings.TrimSpace(header)
+	wait := fallback
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		wait = time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+	}
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
+}
+
+// configuredRate returns the rate limit set by Rate, unlimited without one
+func (s *Scanner) configuredRate() rate.Limit {
+	if s.opts.Rate > 0 {
+		return rate.Limit(s.opts.Rate)
+	}
+	return rate.Inf
+}
+
+// backOff halves the rate shared by all workers after a 429. Workers often
+// hit 429s together, so it slows down at most once per wait, the time the
+// server asked to be left alone.
+func (s *Scanner) backOff(wait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetchesSinceBackoff = 0
+	now := time.Now()
+	if now.Before(s.backoffUntil) {
+		return
+	}
+	if wait < minBackoffInterval {
+		wait = minBackoffInterval
+	}
+	s.backoffUntil = now.Add(wait)
+
+	limit := s.limiter.Limit()
+	next := rate.Limit(backoffRate)
+	if limit != rate.Inf {
+		next = limit / 2
+	}
+	if next < minBackoffRate {
+		next = minBackoffRate
+	}
+	if next < limit {
+		s.limiter.SetLimit(next)
+		s.debugf("Rate limited, slowing down to %g requests per second", float64(next))
+	}
+}
+
+// recoverRate doubles the rate back toward the configured one after
+// recoverAfter fetches in a row weren't rate limited. Without a Rate, the
+// limit is lifted once doubling would pass backoffRate.
+func (s *Scanner) recoverRate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	configured := s.configuredRate()
+	limit := s.limiter.Limit()
+	if limit == configured {
+		return
+	}
+	s.fetchesSinceBackoff++
+	if s.fetchesSinceBackoff < recoverAfter {
+		return
+	}
+	s.fetchesSinceBackoff = 0
+
+	next := limit * 2
+	if (configured != rate.Inf && next > configured) || (configured == rate.Inf && next > backoffRate) {
+		next = configured
+	}
+	s.limiter.SetLimit(next)
+	s.debugf("No longer rate limited, speeding up to %g requests per second", float64(next))
+}
+
+// hostBody releases a host slot when the response body it wraps is closed
+type hostBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b hostBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// fetch waits for the rate limiter and a host slot, then requests rawURL
+// within ctx. The slot is held until the response body is closed. When the
+// server answers 429 Too Many Requests, it releases the slot, waits as long
+// as Retry-After asks, slows the scan down, and tries again up to
+// maxRateLimitRetries times, so the response returned is only a 429 if
+// every retry was rate limited. what names the kind of file in errors,
+// such as "JS file".
+func (s *Scanner) fetch(ctx context.Context, rawURL string, what string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := s.limiter.Wait(ctx); err != nil {
+			// Wait fails early when the deadline would pass first, so the
+			// file is left for the deadline rather than failed
+			if _, ok := ctx.Deadline(); ok {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("rate limiter: %v", err)
+		}
+
+		req, err := s.newRequest(ctx, rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		release := s.acquireHost(rawURL)
+		resp, err := s.fetcher.Do(req)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("%w %s: %v", ErrFetch, what, err)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			s.recoverRate()
+			resp.Body = hostBody{resp.Body, release}
+			return resp, nil
+		}
+
+		wait := retryAfter(resp.Header.Get("Retry-After"), time.Second<<attempt)
+		s.backOff(wait)
+		if attempt == maxRateLimitRetries {
+			fmt.Fprintf(os.Stderr, "Warning: Skipping %s: still rate limited after %d retries\n", rawURL, maxRateLimitRetries)
+			resp.Body = hostBody{resp.Body, release}
+			return resp, nil
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		release()
+		s.debugf("Rate limited fetching %s, retrying in %s", rawURL, wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// rateLimitedReason is the reason recorded for files skipped because every
+// retry was rate limited
+func rateLimitedReason() string {
+	return fmt.Sprintf("rate limited (status 429) after %d retries", maxRateLimitRetries)
+}
@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// rateLimitFetcher answers the first request to each URL in limited with
+// 429 and everything else with 200
+type rateLimitFetcher struct {
+	limited  map[string]bool
+	mu       sync.Mutex
+	answered map[string]bool
+}
+
+func (f *rateLimitFetcher) Do(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status := http.StatusOK
+	if f.limited[req.URL.String()] && !f.answered[req.URL.String()] {
+		status = http.StatusTooManyRequests
+	}
+	f.answered[req.URL.String()] = true
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Retry-After": []string{"0"}},
+		Body:       io.NopCloser(strings.NewReader("ok")),
+		Request:    req,
+	}, nil
+}
+
+func TestFetchBacksOff(t *testing.T) {
+	fetcher := &rateLimitFetcher{
+		limited:  map[string]bool{"https://slow.example.com/app.js": true},
+		answered: make(map[string]bool),
+	}
+	s := New(&config.Config{}, Options{Fetcher: fetcher, PerHostConcurrency: 1})
+
+	resp, err := s.fetch(context.Background(), "https://slow.example.com/app.js", "JS file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want the retry's 200", resp.StatusCode)
+	}
+
+	// The retry waited without the host slot, and the slot is held until
+	// the body is closed
+	select {
+	case s.hostSlots["slow.example.com"] <- struct{}{}:
+		t.Error("the host slot was free while the response body was open")
+	default:
+	}
+	resp.Body.Close()
+	select {
+	case s.hostSlots["slow.example.com"] <- struct{}{}:
+		<-s.hostSlots["slow.example.com"]
+	default:
+		t.Error("closing the response body didn't release the host slot")
+	}
+
+	if s.limiter.Limit() != backoffRate {
+		t.Errorf("rate = %v, want it slowed to %d requests per second", s.limiter.Limit(), backoffRate)
+	}
+}
+
+func TestConcurrentRateLimitsBackOffOnce(t *testing.T) {
+	fetcher := &rateLimitFetcher{limited: make(map[string]bool), answered: make(map[string]bool)}
+	var urls []string
+	for i := 0; i < 8; i++ {
+		url := fmt.Sprintf("https://example.com/%d.js", i)
+		fetcher.limited[url] = true
+		urls = append(urls, url)
+	}
+	s := New(&config.Config{}, Options{Fetcher: fetcher, Rate: 100})
+
+	// Every worker is answered 429 at once, which slows the scan only once
+	var wg sync.WaitGroup
+	for _, url := range urls {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			resp, err := s.fetch(context.Background(), url, "JS file")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}(url)
+	}
+	wg.Wait()
+	if limit := s.limiter.Limit(); limit != 50 {
+		t.Fatalf("rate = %v after a burst of 429s, want it halved once to 50", limit)
+	}
+
+	// Fetches that aren't rate limited restore the configured rate
+	for i := 0; i < recoverAfter; i++ {
+		resp, err := s.fetch(context.Background(), "https://example.com/ok.js", "JS file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if limit := s.limiter.Limit(); limit != 100 {
+		t.Errorf("rate = %v after %d fetches without a 429, want the configured 100", limit, recoverAfter)
+	}
+}
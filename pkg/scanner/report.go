@@ -0,0 +1,173 @@
+package scanner
+
+import (
+	"fmt"
+	"time"
+)
+
+// File statuses recorded in a FileReport
+const (
+	FileScanned = "scanned"
+	FileSkipped = "skipped"
+	FileError   = "error"
+)
+
+// FileReport records what happened when a single file was checked
+type FileReport struct {
+	URL         string `json:"url"`
+	Status      string `json:"status"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	BytesRead   int64  `json:"bytes_read"`
+	// BytesScanned is the size of the text matched, after charset decoding
+	// or extracting WebAssembly strings
+	BytesScanned  int64         `json:"bytes_scanned,omitempty"`
+	MatchDuration time.Duration `json:"match_duration,omitempty"`
+	Reason        string        `json:"reason,omitempty"`
+	// Headers are the capturedHeaders present on the response, recorded
+	// with Options.CaptureHeaders
+	Headers map[string]string `json:"headers,omitempty"`
+	// TLSVersion is the TLS version the file was fetched over, such as 1.3
+	TLSVersion string `json:"tls_version,omitempty"`
+}
+
+// capturedHeaders are the response headers recorded for each file with
+// Options.CaptureHeaders
+var capturedHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"Server",
+	"ETag",
+	"Cache-Control",
+	"Access-Control-Allow-Origin",
+}
+
+// captureHeaders returns the capturedHeaders that get finds a value for,
+// or nil if there are none
+func captureHeaders(get func(name string) string) map[string]string {
+	var headers map[string]string
+	for _, name := range capturedHeaders {
+		if value := get(name); value != "" {
+			if headers == nil {
+				headers = make(map[string]string)
+			}
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+// ScanReport holds the per-file diagnostics collected during a scan
+type ScanReport struct {
+	Files []FileReport `json:"files"`
+}
+
+// Skipped returns the files that were skipped or failed
+func (r ScanReport) Skipped() []FileReport {
+	var skipped []FileReport
+	for _, file := range r.Files {
+		if file.Status != FileScanned {
+			skipped = append(skipped, file)
+		}
+	}
+	return skipped
+}
+
+// GetReport returns the per-file diagnostics collected so far
+func (s *Scanner) GetReport() ScanReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ScanReport{Files: append([]FileReport{}, s.fileReports...)}
+}
+
+// recordFile adds a file's diagnostics to the scan report
+func (s *Scanner) recordFile(report FileReport) {
+	s.mu.Lock()
+	s.fileReports = append(s.fileReports, report)
+	s.mu.Unlock()
+
+	if s.opts.OnFileScanned != nil {
+		s.fileHookMu.Lock()
+		defer s.fileHookMu.Unlock()
+		s.callHook("OnFileScanned", func() { s.opts.OnFileScanned(report) })
+	}
+}
+
+// ScanStats counts what a scan did
+type ScanStats struct {
+	FilesScanned int           `json:"files_scanned"`
+	FilesSkipped int           `json:"files_skipped"`
+	Findings     int           `json:"findings"`
+	Errors       int           `json:"errors"`
+	Duration     time.Duration `json:"duration"`
+	// BytesScanned and MatchDuration total the files' own, so with
+	// concurrency MatchDuration can exceed Duration
+	BytesScanned  int64         `json:"bytes_scanned"`
+	MatchDuration time.Duration `json:"match_duration"`
+}
+
+// Throughput returns the bytes matched per second spent matching, in MB/s
+func (st ScanStats) Throughput() float64 {
+	if st.MatchDuration <= 0 {
+		return 0
+	}
+	return float64(st.BytesScanned) / 1e6 / st.MatchDuration.Seconds()
+}
+
+// String formats the stats as a one-line summary
+func (st ScanStats) String() string {
+	summary := fmt.Sprintf("Scanned %d %s, %d %s, %d %s in %.1fs",
+		st.FilesScanned, plural(st.FilesScanned, "file"),
+		st.Findings, plural(st.Findings, "finding"),
+		st.Errors, plural(st.Errors, "error"),
+		st.Duration.Seconds())
+	if st.BytesScanned > 0 && st.MatchDuration > 0 {
+		summary += fmt.Sprintf(" (%.1f MB matched at %.1f MB/s)", float64(st.BytesScanned)/1e6, st.Throughput())
+	}
+	return summary
+}
+
+// Stats returns the counts collected by the scans run so far. Errors
+// include files that failed to fetch and scans that failed outright.
+func (s *Scanner) Stats() ScanStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := ScanStats{
+		Findings: len(s.findings),
+		Errors:   s.runErrors,
+		Duration: s.runDuration,
+	}
+	for _, file := range s.fileReports {
+		stats.BytesScanned += file.BytesScanned
+		stats.MatchDuration += file.MatchDuration
+		switch file.Status {
+		case FileScanned:
+			stats.FilesScanned++
+		case FileSkipped:
+			stats.FilesSkipped++
+		case FileError:
+			stats.Errors++
+		}
+	}
+	return stats
+}
+
+// recordRun adds a finished scan's duration and outcome to the stats
+func (s *Scanner) recordRun(duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runDuration += duration
+	// A stopped run is reported as partial rather than failed
+	if isStopped(err) {
+		s.stopped = true
+	} else if err != nil {
+		s.runErrors++
+	}
+	if s.opts.PreferSpecific {
+		s.preferSpecific()
+	}
+	if s.fingerprintSuppressed > 0 {
+		s.debugf("Suppressed %d findings by fingerprint", s.fingerprintSuppressed)
+	}
+}
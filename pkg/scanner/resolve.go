@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// ParseHostOverride parses a host:ip DNS override, such as
+// staging.example.com:10.0.0.5. IPv6 addresses may be bracketed.
+func ParseHostOverride(entry string) (host string, ip string, err error) {
+	parts := strings.SplitN(entry, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("invalid host override %q (expected host:ip)", entry)
+	}
+
+	host = strings.ToLower(parts[0])
+	ip = strings.TrimSuffix(strings.TrimPrefix(parts[1], "["), "]")
+	if net.ParseIP(ip) == nil {
+		return "", "", fmt.Errorf("invalid host override %q: %q is not an IP address", entry, ip)
+	}
+	return host, ip, nil
+}
+
+// dialFunc is the signature of net.Dialer.DialContext
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// overrideDial wraps dial to connect to the overridden IP of hosts in
+// overrides. TLS still uses the original host name for SNI and
+// certificate checks.
+func overrideDial(dial dialFunc, overrides map[string]string) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil {
+			if ip, ok := overrides[strings.ToLower(host)]; ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// hostResolverRules returns the Chromium --host-resolver-rules argument
+// applying overrides, or "" if there are none
+func hostResolverRules(overrides map[string]string) string {
+	if len(overrides) == 0 {
+		return ""
+	}
+
+	var rules []string
+	for host, ip := range overrides {
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		rules = append(rules, fmt.Sprintf("MAP %s %s", host, ip))
+	}
+	sort.Strings(rules)
+	return "--host-resolver-rules=" + strings.Join(rules, ", ")
+}
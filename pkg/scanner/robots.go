@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// maxRobotsSize bounds how much of a robots.txt file is read
+const maxRobotsSize = 512 * 1024
+
+// robotsRule is an Allow or Disallow line of robots.txt
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// robotsRules are the rules of robots.txt that apply to jsweb
+type robotsRules []robotsRule
+
+// allowed checks if robots.txt allows fetching path. The longest matching
+// rule wins, and Allow wins ties.
+func (r robotsRules) allowed(path string) bool {
+	allowed, longest := true, -1
+	for _, rule := range r {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > longest || (rule.length == longest && rule.allow) {
+			allowed, longest = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
+
+// fetchRobots fetches and parses origin's robots.txt within ctx, sharing
+// the scan's rate limit. A missing or unreadable file allows everything.
+func (s *Scanner) fetchRobots(ctx context.Context, origin string) robotsRules {
+	resp, err := s.fetch(ctx, origin+"/robots.txt", "robots.txt")
+	if err != nil {
+		s.debugf("Failed to fetch robots.txt for %s: %v", origin, err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil
+	}
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsSize))
+}
+
+// parseRobots returns the rules of the groups for jsweb's user agent, or of
+// the "*" group if none names jsweb
+func parseRobots(r io.Reader) robotsRules {
+	var own, wildcard robotsRules
+	var agents []string
+	inRules := false
+
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := lines.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share the rules that follow
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)}
+			for _, agent := range agents {
+				if agent == "*" {
+					wildcard = append(wildcard, rule)
+				} else if strings.Contains(agent, "jsweb") {
+					own = append(own, rule)
+				}
+			}
+		}
+	}
+
+	if own != nil {
+		return own
+	}
+	return wildcard
+}
+
+// robotsPattern compiles a robots.txt path pattern, where "*" matches any
+// characters and a trailing "$" anchors the end
+func robotsPattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+
+	parts := strings.Split(value, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	pattern := "^" + strings.Join(parts, ".*")
+	if anchored {
+		pattern += "$"
+	}
+	return regexp.MustCompile(pattern)
+}
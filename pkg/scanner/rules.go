@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"github.com/nautical/jsweb/pkg/config"
+	"github.com/nautical/jsweb/pkg/utils"
+)
+
+// RuleInfo describes a rule the scanner runs
+type RuleInfo struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	// EntropyGated is set when matches are dropped outside the rule's
+	// minimum or maximum entropy
+	EntropyGated bool    `json:"entropy_gated"`
+	Entropy      float64 `json:"entropy,omitempty"`
+	MaxEntropy   float64 `json:"max_entropy,omitempty"`
+	// BuiltIn is set for jsweb's own detectors, which have no regex in the
+	// configuration
+	BuiltIn bool `json:"builtin,omitempty"`
+}
+
+// Rules returns the rules the scanner runs, in order, after disabled rules,
+// the built-in detector options, and tag filters are applied. Tag filters
+// are checked against each rule's own tags, so a rule is left out only when
+// none of its findings could be reported.
+func (s *Scanner) Rules() []RuleInfo {
+	var rules []RuleInfo
+	add := func(rule config.Rule, builtIn bool) {
+		if !s.ruleMatchesTagFilters(rule) {
+			return
+		}
+		maxEntropy := s.maxEntropy(rule)
+		tags := rule.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		rules = append(rules, RuleInfo{
+			ID:           rule.ID,
+			Description:  rule.Description,
+			Tags:         tags,
+			EntropyGated: rule.Entropy > 0 || maxEntropy > 0,
+			Entropy:      rule.Entropy,
+			MaxEntropy:   maxEntropy,
+			BuiltIn:      builtIn,
+		})
+	}
+
+	for _, rule := range s.config.Rules {
+		if !utils.Contains(s.config.Extend.DisabledRules, rule.ID) {
+			add(rule, false)
+		}
+	}
+	if !s.opts.DisableJWT {
+		add(jwtRule, true)
+	}
+	if !s.opts.DisablePrivateKey {
+		add(privateKeyRule, true)
+	}
+	if s.opts.ScanObjects {
+		add(objectSecretRule, true)
+	}
+	if s.opts.DetectInternal {
+		for _, id := range []string{privateIPRuleID, internalHostnameRuleID, cloudMetadataRuleID} {
+			add(internalRules[id], true)
+		}
+	}
+
+	return rules
+}
+
+// ruleMatchesTagFilters checks if any finding of rule could pass the tag
+// filters. Findings in comments also carry the in-comment tag.
+func (s *Scanner) ruleMatchesTagFilters(rule config.Rule) bool {
+	if s.matchesTagFilters(Finding{Tags: rule.Tags}) {
+		return true
+	}
+	return !utils.Contains(s.opts.ExcludeTags, inCommentTag) &&
+		s.matchesTagFilters(Finding{Tags: append(append([]string{}, rule.Tags...), inCommentTag)})
+}
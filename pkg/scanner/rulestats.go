@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// RuleStats counts what happened to a rule's regex matches during a scan,
+// for tuning rules
+type RuleStats struct {
+	RuleID string `json:"rule_id"`
+	// Matches counts the regex matches with a secret, once per distinct
+	// secret in a file, before any filtering
+	Matches int `json:"matches"`
+	// Findings counts the matches reported as findings
+	Findings int `json:"findings"`
+	// Allowlisted counts matches suppressed by an allowlist or as a
+	// well-known example secret
+	Allowlisted int `json:"allowlisted"`
+	// ValidationFailed counts matches dropped by StrictValidation
+	ValidationFailed int `json:"validation_failed"`
+	// UUIDFiltered and HashFiltered count matches dropped by FilterUUIDs
+	// and FilterHashes
+	UUIDFiltered int `json:"uuid_filtered"`
+	HashFiltered int `json:"hash_filtered"`
+	// EntropyDropped counts matches below the rule's entropy threshold or
+	// above its ceiling
+	EntropyDropped int `json:"entropy_dropped"`
+	// TooShort counts matches shorter than the minimum secret length
+	TooShort int `json:"too_short"`
+	// Timeouts counts files the rule was skipped on for exceeding
+	// RuleTimeout
+	Timeouts int `json:"timeouts"`
+}
+
+// addRuleStats adds the counts from one scan of content to the totals
+func (s *Scanner) addRuleStats(stats map[string]*RuleStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ruleStats == nil {
+		s.ruleStats = make(map[string]*RuleStats)
+	}
+	for ruleID, counts := range stats {
+		total, ok := s.ruleStats[ruleID]
+		if !ok {
+			total = &RuleStats{RuleID: ruleID}
+			s.ruleStats[ruleID] = total
+		}
+		total.Matches += counts.Matches
+		total.Findings += counts.Findings
+		total.Allowlisted += counts.Allowlisted
+		total.ValidationFailed += counts.ValidationFailed
+		total.UUIDFiltered += counts.UUIDFiltered
+		total.HashFiltered += counts.HashFiltered
+		total.EntropyDropped += counts.EntropyDropped
+		total.TooShort += counts.TooShort
+		total.Timeouts += counts.Timeouts
+	}
+}
+
+// GetRuleStats returns the statistics of every rule that matched or timed
+// out, most matches first. They're only collected with Options.RuleStats.
+func (s *Scanner) GetRuleStats() []RuleStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stats []RuleStats
+	for _, counts := range s.ruleStats {
+		stats = append(stats, *counts)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Matches != stats[j].Matches {
+			return stats[i].Matches > stats[j].Matches
+		}
+		return stats[i].RuleID < stats[j].RuleID
+	})
+	return stats
+}
+
+// PrintRuleStats writes a table of the rule statistics to w, followed by
+// the number of enabled rules that never matched
+func (s *Scanner) PrintRuleStats(w io.Writer) error {
+	stats := s.GetRuleStats()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nRULE\tMATCHES\tFINDINGS\tALLOWLISTED\tINVALID\tUUIDS\tHASHES\tENTROPY DROPPED\tTOO SHORT\tTIMEOUTS")
+	for _, rule := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			rule.RuleID, rule.Matches, rule.Findings, rule.Allowlisted, rule.ValidationFailed, rule.UUIDFiltered, rule.HashFiltered, rule.EntropyDropped, rule.TooShort, rule.Timeouts)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write rule statistics: %v", err)
+	}
+
+	matched := make(map[string]bool)
+	for _, counts := range stats {
+		matched[counts.RuleID] = true
+	}
+	silent := 0
+	for _, rule := range s.Rules() {
+		if !rule.BuiltIn && !matched[rule.ID] {
+			silent++
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%d %s never matched\n", silent, plural(silent, "rule")); err != nil {
+		return fmt.Errorf("failed to write rule statistics: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// runtimeTag is added to the tags of findings in strings captured while
+// the page ran, with RuntimeScan
+const runtimeTag = "runtime"
+
+// runtimeScript runs before any page script when RuntimeScan is set. It
+// wraps eval, the Function constructor, and atob to record the code they
+// run and the strings atob decodes, keeping at most 1000 strings of up to
+// 1 MB each. Wrapped, eval always evaluates in the global scope.
+const runtimeScript = `(() => {
+	const captured = [];
+	Object.defineProperty(window, '__jswebRuntime', { value: captured });
+	const record = (source, value) => {
+		if (typeof value === 'string' && value && captured.length < 1000) {
+			captured.push([source, value.slice(0, 1 << 20)]);
+		}
+	};
+	window.eval = new Proxy(window.eval, {
+		apply(target, thisArg, args) {
+			record('eval', args[0]);
+			return Reflect.apply(target, thisArg, args);
+		},
+	});
+	const functionProxy = new Proxy(Function, {
+		apply(target, thisArg, args) {
+			record('Function', args[args.length - 1]);
+			return Reflect.apply(target, thisArg, args);
+		},
+		construct(target, args, newTarget) {
+			record('Function', args[args.length - 1]);
+			return Reflect.construct(target, args, newTarget);
+		},
+	});
+	Function.prototype.constructor = functionProxy;
+	window.Function = functionProxy;
+	const atob = window.atob;
+	window.atob = function (data) {
+		const decoded = atob.call(this, data);
+		record('atob', decoded);
+		return decoded;
+	};
+})();`
+
+// runtimeCapturedScript returns the strings runtimeScript captured as
+// [source, value] pairs
+const runtimeCapturedScript = `() => window.__jswebRuntime || []`
+
+// runtimeString is a string captured while a page ran
+type runtimeString struct {
+	// Ref names the page, the capturing hook, and a content hash, such as
+	// "https://example.com/ [runtime:eval sha256:3fa2b1c0d9e8]", and is
+	// used as the file of its findings
+	Ref     string
+	Content string
+	// Page is the URL of the page the string was captured on, which
+	// references in it are resolved against
+	Page string
+}
+
+// runtimeInitScript returns runtimeScript as a Playwright init script
+func runtimeInitScript() playwright.Script {
+	return playwright.Script{Content: playwright.String(runtimeScript)}
+}
+
+// collectRuntimeStrings queues the strings captured on page, once per
+// distinct string across the scan
+func (s *Scanner) collectRuntimeStrings(page playwright.Page) error {
+	result, err := page.Evaluate(runtimeCapturedScript)
+	if err != nil {
+		return err
+	}
+	pairs, _ := result.([]interface{})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seenFiles == nil {
+		s.seenFiles = make(map[string]bool)
+	}
+	for _, value := range pairs {
+		pair, ok := value.([]interface{})
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		source, _ := pair[0].(string)
+		content, _ := pair[1].(string)
+		if content == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(content))
+		hash := hex.EncodeToString(sum[:])
+		key := "runtime:" + hash
+		if s.seenFiles[key] {
+			continue
+		}
+		s.seenFiles[key] = true
+		s.pendingRuntime = append(s.pendingRuntime, runtimeString{
+			Ref:     fmt.Sprintf("%s [runtime:%s sha256:%s]", page.URL(), source, hash[:12]),
+			Content: content,
+			Page:    page.URL(),
+		})
+	}
+	return nil
+}
+
+// checkRuntimeStrings scans the queued runtime strings for secrets, tagging
+// their findings runtime and attributing them to target
+func (s *Scanner) checkRuntimeStrings(target string) {
+	s.mu.Lock()
+	captured := s.pendingRuntime
+	s.pendingRuntime = nil
+	s.mu.Unlock()
+
+	if len(captured) > 0 {
+		s.debugf("Scanning %d strings captured at runtime", len(captured))
+	}
+	for _, str := range captured {
+		if s.limitReached() {
+			return
+		}
+		findings := s.findSecrets(str.Ref, str.Content)
+		for i := range findings {
+			findings[i].Tags = append(append([]string{}, findings[i].Tags...), runtimeTag)
+		}
+		setLineNumbers(str.Content, findings)
+		// Findings keep the ref as their file, set by findSecrets, while
+		// chunks and workers the string references resolve against its page
+		s.recordContent(str.Page, target, str.Content, findings)
+	}
+}
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// SampleInfo describes a scan limited to a sample of the discovered files
+// with Options.SampleRate
+type SampleInfo struct {
+	Rate         float64 `json:"rate"`
+	TotalFiles   int     `json:"total_files"`
+	SampledFiles int     `json:"sampled_files"`
+}
+
+// String formats the sample as a one-line note
+func (si SampleInfo) String() string {
+	return fmt.Sprintf("Sampled scan at rate %g: %d of %d discovered %s selected",
+		si.Rate, si.SampledFiles, si.TotalFiles, plural(si.TotalFiles, "file"))
+}
+
+// sampling checks if the scan only checks a sample of the discovered files
+func (s *Scanner) sampling() bool {
+	return s.opts.SampleRate > 0 && s.opts.SampleRate < 1
+}
+
+// inSample checks if fileURL is in the sample. The choice hashes the
+// file's dedupe key, so every run with the same rate picks the same files.
+func (s *Scanner) inSample(fileURL string) bool {
+	h := fnv.New64a()
+	h.Write([]byte(s.dedupeKey(fileURL)))
+	return float64(h.Sum64())/(1<<64) < s.opts.SampleRate
+}
+
+// sampleFiles returns the files in the sample, counting each distinct
+// file once for Sample
+func (s *Scanner) sampleFiles(files []string) []string {
+	if !s.sampling() {
+		return files
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sampled == nil {
+		s.sampled = make(map[string]bool)
+	}
+	var kept []string
+	for _, file := range files {
+		key := s.dedupeKey(file)
+		selected, ok := s.sampled[key]
+		if !ok {
+			selected = s.inSample(file)
+			s.sampled[key] = selected
+		}
+		if selected {
+			kept = append(kept, file)
+		} else {
+			s.debugf("Skipping %s, not in the sample", file)
+		}
+	}
+	return kept
+}
+
+// Sample returns how many of the discovered files were selected, or nil
+// if the scan isn't sampled
+func (s *Scanner) Sample() *SampleInfo {
+	if !s.sampling() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := &SampleInfo{Rate: s.opts.SampleRate, TotalFiles: len(s.sampled)}
+	for _, selected := range s.sampled {
+		if selected {
+			info.SampledFiles++
+		}
+	}
+	return info
+}
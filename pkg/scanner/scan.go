@@ -0,0 +1,653 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nautical/jsweb/pkg/config"
+	"github.com/nautical/jsweb/pkg/utils"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ScanOptions configures a complete scan driven by Scan
+type ScanOptions struct {
+	// Options are the scanner settings (headers, cookies, proxy, ...)
+	Options
+	// RunOptions are the pages to scan and how to find them
+	RunOptions
+
+	// Config is the detection configuration to scan with
+	Config *config.Config
+}
+
+// RunOptions configures one Scanner.Run: the pages whose JavaScript files
+// are scanned and how to find them. Scanner settings come from the Options
+// the Scanner was created with.
+type RunOptions struct {
+	// URLs are the pages whose JavaScript files will be scanned
+	URLs []string
+	// Concurrency is the number of JavaScript files checked in parallel
+	Concurrency int
+	// Sitemap also scans the pages listed in each URL's /sitemap.xml
+	Sitemap bool
+	// MaxPages caps the number of pages taken from sitemaps (0 for no limit)
+	MaxPages int
+	// ScopePrefix only takes sitemap pages whose path starts with it, e.g.
+	// /app/, keeping the scan within one part of a site
+	ScopePrefix string
+	// CTSubdomains also scans the live subdomains of each URL's registrable
+	// domain found in certificate transparency logs
+	CTSubdomains bool
+	// CTLogURL is the crt.sh-compatible search queried with CTSubdomains
+	// (crt.sh when empty)
+	CTLogURL string
+	// MaxHosts caps the number of hosts scanned with CTSubdomains,
+	// including the URLs' own (0 for no limit)
+	MaxHosts int
+}
+
+// Scan launches a browser, visits each URL in opts, and checks every
+// JavaScript file found for secrets, returning all findings
+func Scan(ctx context.Context, opts ScanOptions) ([]Finding, error) {
+	if opts.Config == nil {
+		return nil, fmt.Errorf("scan options must include a config")
+	}
+
+	s := New(opts.Config, opts.Options)
+	if err := s.Run(ctx, opts.RunOptions); err != nil {
+		return nil, err
+	}
+	return s.GetFindings(), nil
+}
+
+// Run launches a browser (unless NoBrowser is set) and scans the JavaScript
+// files of every URL in opts, collecting findings on the Scanner. Scanner
+// settings come from the Options the Scanner was created with.
+func (s *Scanner) Run(ctx context.Context, opts RunOptions) error {
+	if s.optionsErr != nil {
+		return s.optionsErr
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := s.loadCheckpoint()
+	if err == nil {
+		err = s.run(ctx, opts)
+	}
+	if err == nil {
+		s.removeCheckpoint()
+	}
+	s.recordRun(time.Since(start), err)
+	return err
+}
+
+// run performs the scan for Run
+func (s *Scanner) run(ctx context.Context, opts RunOptions) error {
+	for _, target := range opts.URLs {
+		if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: %s", ErrInvalidURL, target)
+		}
+	}
+
+	// Source maps given directly are fetched and scanned without a browser
+	var mapURLs, pageURLs []string
+	for _, target := range opts.URLs {
+		if isSourceMapURL(target) {
+			mapURLs = append(mapURLs, target)
+		} else {
+			pageURLs = append(pageURLs, target)
+		}
+	}
+	s.checkSourceMaps(ctx, mapURLs)
+	if len(pageURLs) == 0 {
+		return ctx.Err()
+	}
+
+	if opts.CTSubdomains {
+		pageURLs = s.subdomainPages(ctx, pageURLs, opts.CTLogURL, opts.MaxHosts, opts.Concurrency)
+	}
+	pages := s.targetPages(ctx, pageURLs, opts.Sitemap, opts.MaxPages, opts.ScopePrefix)
+
+	// Take scripts from the pages' HTML without starting a browser if requested
+	if s.opts.NoBrowser {
+		if s.opts.LoginScript != nil {
+			return fmt.Errorf("a login script requires a browser and can't be used with NoBrowser")
+		}
+		return s.scanPages(ctx, pages, opts.Concurrency, s.fetchPageScripts)
+	}
+
+	// Install browsers on first use
+	if err := s.EnsureBrowsers(); err != nil {
+		return err
+	}
+
+	// Initialize Playwright
+	pw, err := playwright.Run(playwrightOptions())
+	if err != nil {
+		return fmt.Errorf("%w: failed to initialize Playwright: %v", ErrBrowserLaunch, err)
+	}
+	defer pw.Stop()
+
+	// Create browser, closed on return including on cancellation
+	openPage, closeBrowser, err := s.launchBrowser(pw)
+	if err != nil {
+		return err
+	}
+	defer closeBrowser()
+
+	// Log in first so the session cookies apply to every page and fetch
+	if s.opts.LoginScript != nil && len(pages) > 0 {
+		if err := s.login(openPage, pages[0].URL); err != nil {
+			return err
+		}
+	}
+
+	return s.scanPages(ctx, pages, opts.Concurrency, func(ctx context.Context, pageURL string) ([]string, error) {
+		return s.discoverPage(openPage, pageURL)
+	})
+}
+
+// scanPages checks the JavaScript files that discover finds on each page,
+// followed by the workers and chunks they load
+func (s *Scanner) scanPages(ctx context.Context, pages []targetPage, concurrency int, discover func(ctx context.Context, pageURL string) ([]string, error)) error {
+	probed := make(map[string]bool)
+	for i, page := range pages {
+		if err := ctx.Err(); err != nil {
+			s.addUnchecked(0, len(pages)-i)
+			return err
+		}
+		if s.limitReached() {
+			s.debugf("Reached %d findings, skipping the remaining pages", s.opts.MaxFindings)
+			break
+		}
+
+		s.addOrigin(page.URL)
+		if s.pageDone(page.URL) {
+			s.debugf("Skipping %s, scanned before the checkpoint", page.URL)
+			continue
+		}
+		jsFiles, err := discover(ctx, page.URL)
+		if errors.Is(err, ErrFetch) && ctx.Err() == nil && !s.opts.FailOnNavError {
+			// One unreachable page doesn't end the scan of the others
+			fmt.Fprintf(os.Stderr, "Warning: Skipping page: %v\n", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		s.markSeen(jsFiles)
+
+		// Probe the wordlist once per host for files no page references
+		if len(s.opts.Wordlist) > 0 {
+			if host := TargetHost(page.URL); !probed[host] {
+				probed[host] = true
+				probedFiles := s.probeWordlist(ctx, page.URL, concurrency)
+				s.markSeen(probedFiles)
+				jsFiles = append(jsFiles, probedFiles...)
+			}
+		}
+
+		jsFiles = s.pendingFiles(jsFiles)
+		// The page is only recorded as done if all its files were checked,
+		// so a resumed scan retries those that failed
+		checked, complete := s.checkFiles(ctx, page.Target, jsFiles, concurrency)
+		s.checkAttributes(page.Target)
+		s.checkStorage(page.Target)
+		s.checkPageHTML(page.Target)
+		s.checkRuntimeStrings(page.Target)
+		s.checkPageFindings(page.Target)
+		s.checkInlineScripts(page.Target)
+		if ctx.Err() == nil {
+			s.saveCheckpoint("", checked)
+		}
+
+		// Check worker scripts and lazily loaded chunks, which never appear
+		// as script tags. Chunks are followed up to the depth limit.
+		for depth := 1; ; depth++ {
+			files := s.takeWorkers()
+			if depth <= s.opts.ChunkDepth {
+				s.fetchViteManifests(ctx)
+				chunks := s.takeChunks()
+				if len(chunks) > 0 {
+					s.debugf("Found %d chunks at depth %d", len(chunks), depth)
+				}
+				files = append(files, chunks...)
+			}
+			if len(files) == 0 {
+				break
+			}
+			checked, ok := s.checkFiles(ctx, page.Target, files, concurrency)
+			complete = complete && ok
+			if ctx.Err() == nil {
+				s.saveCheckpoint("", checked)
+			}
+		}
+
+		// Drop chunks found beyond the depth limit
+		s.takeChunks()
+		if ctx.Err() == nil && complete {
+			s.saveCheckpoint(page.URL, nil)
+		}
+	}
+
+	if ctx.Err() == nil {
+		s.checkCorpora()
+	}
+	return ctx.Err()
+}
+
+// pageOpener opens a new blank page in the scan's browser
+type pageOpener func() (playwright.Page, error)
+
+// launchBrowser starts Chromium and returns a function opening pages in it
+// and one closing it. Each page gets its own browser context, unless
+// UserDataDir is set, in which case every page shares one persistent
+// context stored in that directory.
+func (s *Scanner) launchBrowser(pw *playwright.Playwright) (pageOpener, func(), error) {
+	var proxy *playwright.Proxy
+	if s.opts.Proxy != "" {
+		proxy = &playwright.Proxy{Server: s.opts.Proxy}
+	}
+
+	// Look like a regular desktop browser if requested
+	var viewport *playwright.Size
+	var locale *string
+	if s.opts.Stealth {
+		viewport = stealthViewport
+		locale = playwright.String(stealthLocale)
+	}
+
+	if s.opts.UserDataDir != "" {
+		s.debugf("Using the persistent browser profile in %s", s.opts.UserDataDir)
+		browserContext, err := pw.Chromium.LaunchPersistentContext(s.opts.UserDataDir, playwright.BrowserTypeLaunchPersistentContextOptions{
+			Args:      s.browserArgs(),
+			Headless:  playwright.Bool(!s.opts.Headed),
+			Proxy:     proxy,
+			UserAgent: playwright.String(s.userAgent()),
+			Viewport:  viewport,
+			Screen:    viewport,
+			Locale:    locale,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrBrowserLaunch, err)
+		}
+		if s.opts.Stealth {
+			if err := browserContext.AddInitScript(stealthInitScript()); err != nil {
+				browserContext.Close()
+				return nil, nil, fmt.Errorf("%w: failed to add stealth script: %v", ErrBrowserLaunch, err)
+			}
+		}
+		return browserContext.NewPage, func() { browserContext.Close() }, nil
+	}
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Args:     s.browserArgs(),
+		Headless: playwright.Bool(!s.opts.Headed),
+		Proxy:    proxy,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrBrowserLaunch, err)
+	}
+	openPage := func() (playwright.Page, error) {
+		page, err := browser.NewPage(playwright.BrowserNewPageOptions{
+			UserAgent: playwright.String(s.userAgent()),
+			Viewport:  viewport,
+			Screen:    viewport,
+			Locale:    locale,
+		})
+		if err != nil || !s.opts.Stealth {
+			return page, err
+		}
+		if err := page.AddInitScript(stealthInitScript()); err != nil {
+			page.Close()
+			return nil, fmt.Errorf("failed to add stealth script: %v", err)
+		}
+		return page, nil
+	}
+	return openPage, func() { browser.Close() }, nil
+}
+
+// containerBrowserArgs are the Chromium arguments needed to run inside a
+// container, where the sandbox is unavailable and /dev/shm is small
+var containerBrowserArgs = []string{"--no-sandbox", "--disable-dev-shm-usage"}
+
+// browserArgs returns the Chromium launch arguments, adding the container
+// defaults when running inside one
+func (s *Scanner) browserArgs() []string {
+	args := append([]string{}, s.opts.BrowserArgs...)
+	if rules := hostResolverRules(s.opts.HostOverrides); rules != "" {
+		args = append(args, rules)
+	}
+	if version, ok := chromiumTLSVersions[s.opts.MinTLSVersion]; ok {
+		args = append(args, "--ssl-version-min="+version)
+	}
+	if s.opts.Stealth && !utils.Contains(args, stealthBrowserArg) {
+		args = append(args, stealthBrowserArg)
+	}
+	if !inContainer() {
+		return args
+	}
+
+	for _, arg := range containerBrowserArgs {
+		if !utils.Contains(args, arg) {
+			args = append(args, arg)
+		}
+	}
+	s.debugf("Running in a container, launching the browser with %s", strings.Join(args, " "))
+	return args
+}
+
+// inContainer checks if the process appears to run inside a container
+func inContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+
+	cgroup, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	for _, name := range []string{"docker", "kubepods", "containerd", "lxc"} {
+		if strings.Contains(string(cgroup), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetPage is a page to scan and the target it was found from
+type targetPage struct {
+	URL    string
+	Target string
+}
+
+// targetPages returns the pages to scan for targets, adding the same-origin
+// pages under scopePrefix listed in each target's sitemap if enabled
+func (s *Scanner) targetPages(ctx context.Context, targets []string, sitemap bool, maxPages int, scopePrefix string) []targetPage {
+	seen := make(map[string]bool)
+	var pages []targetPage
+	for _, target := range targets {
+		if key := s.pageKey(target); !seen[key] {
+			seen[key] = true
+			pages = append(pages, targetPage{URL: target, Target: target})
+		}
+		if !sitemap {
+			continue
+		}
+
+		sitemapPages, err := s.sitemapURLs(ctx, target, maxPages, scopePrefix)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to read sitemap for %s: %v\n", target, err)
+			continue
+		}
+		s.debugf("Found %d pages in the sitemap for %s", len(sitemapPages), target)
+
+		for _, page := range sitemapPages {
+			if key := s.pageKey(page); !seen[key] {
+				seen[key] = true
+				pages = append(pages, targetPage{URL: page, Target: target})
+			}
+		}
+	}
+	return pages
+}
+
+// discoverPage opens pageURL in a new page and returns its JavaScript files
+func (s *Scanner) discoverPage(openPage pageOpener, pageURL string) ([]string, error) {
+	page, err := s.newPage(openPage, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer page.Close()
+	// Save the trace even when navigation or discovery fails
+	defer s.startTrace(page, pageURL)()
+
+	// Navigate to URL, waiting for the configured load state. Collect the
+	// scripts of whatever loaded if navigation fails, unless navigation
+	// errors should end the scan.
+	response, navErr := page.Goto(pageURL, s.gotoOptions())
+	if navErr != nil {
+		if s.opts.FailOnNavError {
+			return nil, fmt.Errorf("%w %s: %v", ErrFetch, pageURL, navErr)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: Navigation to %s failed, scanning what loaded: %v\n", pageURL, navErr)
+	}
+
+	// Let dynamic pages settle before collecting their scripts, unless the
+	// page didn't load
+	if navErr == nil && s.opts.WaitSelector != "" {
+		s.debugf("Waiting for %s on %s", s.opts.WaitSelector, pageURL)
+		if err := page.Locator(s.opts.WaitSelector).WaitFor(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s didn't appear on %s: %v\n", s.opts.WaitSelector, pageURL, err)
+		}
+	}
+	if navErr == nil && s.opts.WaitDelay > 0 {
+		page.WaitForTimeout(float64(s.opts.WaitDelay.Milliseconds()))
+	}
+	if navErr == nil && s.opts.Scroll {
+		s.scrollPage(page)
+	}
+
+	// Find JavaScript files
+	jsFiles, err := s.FindJSFiles(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find JavaScript files: %v", err)
+	}
+
+	// Warn about, and retry if enabled, pages that look like discovery
+	// failed
+	if navErr == nil && len(jsFiles) == 0 {
+		if jsFiles, err = s.retryEmptyPage(page, pageURL); err != nil {
+			return nil, err
+		}
+	}
+
+	// Collect inline handlers and data attributes if enabled. A page that
+	// can't be evaluated still has its scripts scanned.
+	if s.opts.ScanAttributes {
+		if result, err := page.Evaluate(attributesScript); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to find inline attributes on %s: %v\n", pageURL, err)
+		} else {
+			s.addAttributes(evaluatedAttributes(page.URL(), result))
+		}
+	}
+
+	// Collect what eval, Function, and atob saw if enabled
+	if s.opts.RuntimeScan {
+		if err := s.collectRuntimeStrings(page); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to collect runtime strings on %s: %v\n", pageURL, err)
+		}
+	}
+
+	// Collect the rendered HTML if enabled
+	if s.opts.ScanHTML {
+		if content, err := page.Content(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to read the HTML of %s: %v\n", pageURL, err)
+		} else {
+			s.addPageHTML(page.URL(), "text/html; charset=utf-8", []byte(content))
+		}
+	}
+
+	// Check the policy the page was served with if enabled. A policy only
+	// partly read could look missing, so the check is skipped instead.
+	if s.opts.ReportCSP && response != nil {
+		if policies, err := pageCSP(page, response); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Skipping the Content-Security-Policy check of %s: %v\n", pageURL, err)
+		} else {
+			s.addCSP(page.URL(), policies)
+		}
+	}
+
+	// Collect the tokens the page stored at runtime if enabled
+	if s.opts.ScanStorage {
+		if result, err := page.Evaluate(storageScript); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to read page storage on %s: %v\n", pageURL, err)
+		} else {
+			s.addStorage(evaluatedStorage(page.URL(), result))
+		}
+	}
+
+	return jsFiles, nil
+}
+
+// pageCSP returns the Content-Security-Policy of a loaded page, from its
+// response headers and meta tags
+func pageCSP(page playwright.Page, response playwright.Response) ([]string, error) {
+	headers, err := response.HeaderValues("content-security-policy")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Content-Security-Policy header: %v", err)
+	}
+	policies := splitCSPHeader(headers)
+	result, err := page.Evaluate(cspMetaScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Content-Security-Policy meta tags: %v", err)
+	}
+	values, _ := result.([]interface{})
+	for _, value := range values {
+		if policy, ok := value.(string); ok {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// gotoOptions returns the navigation options waiting for the configured
+// load state
+func (s *Scanner) gotoOptions() playwright.PageGotoOptions {
+	options := playwright.PageGotoOptions{}
+	if s.opts.WaitUntil != "" {
+		options.WaitUntil = (*playwright.WaitUntilState)(&s.opts.WaitUntil)
+	}
+	return options
+}
+
+// newPage opens a page carrying the configured user agent, headers, and
+// cookies (simple cookies are scoped to pageURL)
+func (s *Scanner) newPage(openPage pageOpener, pageURL string) (playwright.Page, error) {
+	page, err := openPage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page: %v", err)
+	}
+
+	// Hook eval, Function, and atob before the page's scripts run
+	if s.opts.RuntimeScan {
+		if err := page.AddInitScript(runtimeInitScript()); err != nil {
+			page.Close()
+			return nil, fmt.Errorf("failed to add runtime script: %v", err)
+		}
+	}
+
+	// Set headers if provided
+	if len(s.headers) > 0 {
+		playwrightHeaders := make(map[string]string)
+		for key := range s.headers {
+			playwrightHeaders[key] = s.headers.Get(key)
+		}
+
+		if err := page.SetExtraHTTPHeaders(playwrightHeaders); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting headers: %v\n", err)
+		}
+	}
+
+	// Set cookies if provided
+	cookies := append(parseCookies(s.cookies, pageURL), playwrightCookies(s.opts.DomainCookies)...)
+	if len(cookies) > 0 {
+		if err := page.Context().AddCookies(cookies); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting cookies: %v\n", err)
+		}
+	}
+
+	return page, nil
+}
+
+// checkFiles checks each file found for target for secrets using up to
+// concurrency workers. It returns the files that were checked without
+// error, and whether none failed.
+func (s *Scanner) checkFiles(ctx context.Context, target string, jsFiles []string, concurrency int) ([]string, bool) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jsFiles = s.sampleFiles(jsFiles)
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	var doneMu sync.Mutex
+	var done []string
+	failed := false
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for jsFile := range jobs {
+				if err := s.checkFile(ctx, jsFile, target); err != nil {
+					fmt.Fprintf(os.Stderr, "Error checking file %s: %v\n", jsFile, err)
+					doneMu.Lock()
+					failed = true
+					doneMu.Unlock()
+					continue
+				}
+				doneMu.Lock()
+				done = append(done, jsFile)
+				doneMu.Unlock()
+			}
+		}()
+	}
+
+	for i, jsFile := range jsFiles {
+		if ctx.Err() != nil {
+			s.addUnchecked(len(jsFiles)-i, 0)
+			break
+		}
+		if s.limitReached() {
+			break
+		}
+		jobs <- jsFile
+	}
+	close(jobs)
+	wg.Wait()
+	return done, !failed
+}
+
+// parseCookies converts a 'name=value; name2=value2' string into Playwright
+// cookies scoped to pageURL
+func parseCookies(cookieStr string, pageURL string) []playwright.OptionalCookie {
+	var cookies []playwright.OptionalCookie
+	for _, cookie := range strings.Split(cookieStr, ";") {
+		cookie = strings.TrimSpace(cookie)
+		if cookie == "" {
+			continue
+		}
+
+		parts := strings.SplitN(cookie, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		if name != "" && value != "" {
+			cookies = append(cookies, playwright.OptionalCookie{
+				Name:  name,
+				Value: value,
+				URL:   playwright.String(pageURL),
+			})
+		}
+	}
+	return cookies
+}
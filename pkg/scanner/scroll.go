@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultScrollSteps bounds the scrolls of a page when Options.ScrollSteps
+// is unset, so never-ending feeds don't scroll forever
+const DefaultScrollSteps = 10
+
+// scrollStepScript scrolls to the bottom of the page, then waits up to 5s
+// for no new resources to finish loading for 500ms, and returns the new
+// page height
+const scrollStepScript = `async () => {
+	window.scrollTo(0, document.documentElement.scrollHeight);
+	let count = performance.getEntriesByType('resource').length;
+	for (let idle = 0, waited = 0; idle < 500 && waited < 5000; waited += 100) {
+		await new Promise(resolve => setTimeout(resolve, 100));
+		const now = performance.getEntriesByType('resource').length;
+		idle = now === count ? idle + 100 : 0;
+		count = now;
+	}
+	return document.documentElement.scrollHeight;
+}`
+
+// scrollPage scrolls page to the bottom in steps so lazy-loaded scripts get
+// fetched, stopping once the page stops growing or after the configured
+// number of steps
+func (s *Scanner) scrollPage(page playwright.Page) {
+	steps := s.opts.ScrollSteps
+	if steps <= 0 {
+		steps = DefaultScrollSteps
+	}
+	lastHeight := -1.0
+	for step := 1; step <= steps; step++ {
+		result, err := page.Evaluate(scrollStepScript)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to scroll %s: %v\n", page.URL(), err)
+			return
+		}
+		height, _ := toFloat(result)
+		if height <= lastHeight {
+			s.debugf("Stopped scrolling %s after %d steps", page.URL(), step)
+			return
+		}
+		lastHeight = height
+	}
+	s.debugf("Stopped scrolling %s at the %d step limit", page.URL(), steps)
+}
+
+// toFloat converts a number returned by page.Evaluate
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
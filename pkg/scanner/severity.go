@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/config"
+	"github.com/nautical/jsweb/pkg/utils"
+)
+
+// Severities lists the finding severities from lowest to highest
+var Severities = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// severityRank orders severities for sorting and MinSeverity. Findings
+// without a severity rank as medium.
+func severityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return 1
+}
+
+// IsValidSeverity checks if severity is one of Severities
+func IsValidSeverity(severity string) bool {
+	return utils.Contains(Severities, severity)
+}
+
+// ruleSeverity returns the severity a rule declares with its severity key,
+// or else the one its tags name, as "high" or "severity:high", or "" if
+// neither does. Unknown severities, warned about by Validate, are ignored.
+func ruleSeverity(rule config.Rule) string {
+	if severity := strings.ToLower(rule.Severity); IsValidSeverity(severity) {
+		return severity
+	}
+	for _, tag := range rule.Tags {
+		tag = strings.ToLower(tag)
+		tag = strings.TrimPrefix(strings.TrimPrefix(tag, "severity:"), "severity-")
+		if IsValidSeverity(tag) {
+			return tag
+		}
+	}
+	return ""
+}
+
+// meetsMinSeverity checks if a finding is at least MinSeverity
+func (s *Scanner) meetsMinSeverity(finding Finding) bool {
+	return s.opts.MinSeverity == "" || AtLeastSeverity(finding.Severity, s.opts.MinSeverity)
+}
+
+// AtLeastSeverity checks if severity is min or higher. An empty severity
+// counts as medium.
+func AtLeastSeverity(severity string, min string) bool {
+	return severityRank(severity) >= severityRank(min)
+}
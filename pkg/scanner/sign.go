@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SignatureExt is appended to a report's path to name its detached
+// signature
+const SignatureExt = ".sig"
+
+// LoadSigningKey reads an ed25519 private key from a PEM file in PKCS #8
+// form, as written by 'openssl genpkey -algorithm ed25519'
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %v", path, err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s isn't an ed25519 key", path)
+	}
+	return private, nil
+}
+
+// LoadVerifyKey reads an ed25519 public key from a PEM file in PKIX form,
+// as written by 'openssl pkey -pubout'
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %v", path, err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s isn't an ed25519 key", path)
+	}
+	return public, nil
+}
+
+// readPEM reads the first PEM block of path, which must have the given type
+func readPEM(path string, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s isn't a PEM-encoded %s", path, strings.ToLower(blockType))
+	}
+	return block, nil
+}
+
+// SignFile writes a detached ed25519 signature over the contents of path
+// to path with SignatureExt appended, base64-encoded on one line. It
+// returns the signature's path.
+func SignFile(path string, key ed25519.PrivateKey) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report: %v", err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)) + "\n"
+	sigPath := path + SignatureExt
+	if err := os.WriteFile(sigPath, []byte(signature), 0644); err != nil {
+		return "", fmt.Errorf("failed to write signature: %v", err)
+	}
+	return sigPath, nil
+}
+
+// VerifyFile checks that sigPath holds a signature over the contents of
+// path made by the private half of key, returning ErrBadSignature if not
+func VerifyFile(path string, sigPath string, key ed25519.PublicKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %v", err)
+	}
+	encoded, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %v", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: %s isn't an ed25519 signature", ErrBadSignature, sigPath)
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("%w: %s doesn't match %s", ErrBadSignature, sigPath, path)
+	}
+	return nil
+}
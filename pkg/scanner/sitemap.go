@@ -0,0 +1,161 @@
+package scanner
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// maxSitemapDepth limits how deeply nested sitemap indexes are followed
+const maxSitemapDepth = 3
+
+// sitemapDocument covers both <urlset> sitemaps and <sitemapindex> indexes
+type sitemapDocument struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// SitemapURLs fetches /sitemap.xml for the origin of target, following
+// nested sitemap indexes, and returns up to maxPages same-origin page URLs
+// (0 for no limit). Fetches share the scan's rate limit and stop when ctx
+// is done.
+func (s *Scanner) SitemapURLs(ctx context.Context, target string, maxPages int) ([]string, error) {
+	return s.sitemapURLs(ctx, target, maxPages, "")
+}
+
+// sitemapURLs is SitemapURLs keeping only pages whose path starts with
+// scopePrefix
+func (s *Scanner) sitemapURLs(ctx context.Context, target string, maxPages int, scopePrefix string) ([]string, error) {
+	origin, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+
+	sitemapURL := &url.URL{Scheme: origin.Scheme, Host: origin.Host, Path: "/sitemap.xml"}
+	collector := sitemapCollector{
+		scanner:  s,
+		origin:   origin,
+		prefix:   scopePrefix,
+		maxPages: maxPages,
+		visited:  make(map[string]bool),
+		seen:     make(map[string]bool),
+	}
+	if err := collector.collect(ctx, sitemapURL.String(), 0); err != nil {
+		return nil, err
+	}
+	return collector.pages, nil
+}
+
+// sitemapCollector accumulates page URLs across a tree of sitemaps
+type sitemapCollector struct {
+	scanner  *Scanner
+	origin   *url.URL
+	prefix   string
+	maxPages int
+	visited  map[string]bool
+	seen     map[string]bool
+	pages    []string
+}
+
+// full reports whether the page limit has been reached
+func (c *sitemapCollector) full() bool {
+	return c.maxPages > 0 && len(c.pages) >= c.maxPages
+}
+
+// collect fetches a sitemap and records its pages, recursing into indexes
+func (c *sitemapCollector) collect(ctx context.Context, sitemapURL string, depth int) error {
+	if c.visited[sitemapURL] || depth > maxSitemapDepth || c.full() {
+		return nil
+	}
+	c.visited[sitemapURL] = true
+
+	doc, err := c.scanner.fetchSitemap(ctx, sitemapURL)
+	if err != nil {
+		// Only the root sitemap is required; broken nested ones are skipped
+		if depth == 0 {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: Skipping sitemap %s: %v\n", sitemapURL, err)
+		return nil
+	}
+
+	for _, entry := range doc.URLs {
+		if c.full() {
+			return nil
+		}
+		page := strings.TrimSpace(entry.Loc)
+		if !c.sameOrigin(page) || !c.inScope(page) || c.seen[page] {
+			continue
+		}
+		c.seen[page] = true
+		c.pages = append(c.pages, page)
+	}
+
+	for _, entry := range doc.Sitemaps {
+		nested := strings.TrimSpace(entry.Loc)
+		if !c.sameOrigin(nested) {
+			continue
+		}
+		if err := c.collect(ctx, nested, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameOrigin checks if rawURL shares the target's scheme and host
+func (c *sitemapCollector) sameOrigin(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == c.origin.Scheme && strings.EqualFold(u.Host, c.origin.Host)
+}
+
+// inScope checks if rawURL's path starts with the scope prefix
+func (c *sitemapCollector) inScope(rawURL string) bool {
+	if c.prefix == "" {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	return strings.HasPrefix(path, c.prefix)
+}
+
+// fetchSitemap fetches and parses a single sitemap document within ctx
+func (s *Scanner) fetchSitemap(ctx context.Context, sitemapURL string) (*sitemapDocument, error) {
+	resp, err := s.fetch(ctx, sitemapURL, "sitemap")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch sitemap %s: status %d", sitemapURL, resp.StatusCode)
+	}
+
+	var body io.Reader = resp.Body
+	if s.opts.MaxFileSize > 0 {
+		body = io.LimitReader(resp.Body, s.opts.MaxFileSize)
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap %s: %v", sitemapURL, err)
+	}
+	return &doc, nil
+}
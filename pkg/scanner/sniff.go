@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// sniffLength is how much of a response body is inspected to tell whether
+// a file served with a generic content type is JavaScript
+const sniffLength = 512
+
+// genericContentTypes are the content types servers send when they don't
+// know what a file is
+var genericContentTypes = []string{
+	"application/octet-stream",
+	"binary/octet-stream",
+	"application/unknown",
+}
+
+// jsPrefixes are the ways JavaScript files commonly start, after any
+// leading whitespace
+var jsPrefixes = []string{
+	"/*!", "/*", "//",
+	"!function", "!(function", "(function", "(()=>", "(() =>", "(async",
+	`"use strict"`, "'use strict'",
+	"var ", "let ", "const ", "function ", "function(", "async function", "class ",
+	"import ", "import{", "import(", "export ", "export{",
+	"define(", "System.register(", "window.", "self.", "globalThis.",
+}
+
+// jsMarkers are tokens that give away bundled JavaScript anywhere in the
+// inspected head
+var jsMarkers = []string{"__webpack_require__", "webpackChunk", "webpackJsonp", "sourceMappingURL="}
+
+// isGenericContentType checks if contentType is missing or says nothing
+// about the file
+func isGenericContentType(contentType string) bool {
+	if strings.TrimSpace(contentType) == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, generic := range genericContentTypes {
+		if mediaType == generic {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeJavaScript checks if head, the start of a file, looks like
+// JavaScript: text without NUL bytes that starts like a script or contains
+// a bundler marker. Markup and binary data don't qualify.
+func looksLikeJavaScript(head []byte) bool {
+	if len(head) > sniffLength {
+		head = head[:sniffLength]
+	}
+	head = bytes.TrimPrefix(head, []byte{0xEF, 0xBB, 0xBF})
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+	text := strings.TrimSpace(string(head))
+	if text == "" || strings.HasPrefix(text, "<") {
+		return false
+	}
+	for _, prefix := range jsPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	for _, marker := range jsMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// peekBody returns up to sniffLength bytes from the start of resp's body
+// for sniffing, leaving them to be read again from resp.Body
+func peekBody(resp *http.Response) []byte {
+	reader := bufio.NewReaderSize(resp.Body, sniffLength)
+	head, _ := reader.Peek(sniffLength)
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{reader, resp.Body}
+	return head
+}
+
+// sniffScannable checks if a file served with a missing or generic content
+// type should still be scanned, because its URL has a scannable extension or
+// head, the start of its body, looks like JavaScript
+func (s *Scanner) sniffScannable(contentType string, fileURL string, head []byte) bool {
+	return isGenericContentType(contentType) && (s.isScannableFile(fileURL) || looksLikeJavaScript(head))
+}
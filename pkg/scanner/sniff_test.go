@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+func TestLooksLikeJavaScript(t *testing.T) {
+	tests := []struct {
+		head string
+		want bool
+	}{
+		{"(function(){var a=1})();", true},
+		{"\xef\xbb\xbf  'use strict';", true},
+		{"x=1;self.webpackChunkapp=self.webpackChunkapp||[]", true},
+		{"<!DOCTYPE html><html>", false},
+		{"\x00asm\x01\x00\x00\x00", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := looksLikeJavaScript([]byte(tt.head)); got != tt.want {
+			t.Errorf("looksLikeJavaScript(%q) = %v, want %v", tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestProbeFileSniffsBody(t *testing.T) {
+	fetcher := stubFetcher{
+		bodies: map[string]string{
+			"https://example.com/static/app":   "/*! app v1 */\n(function(){var key=1})();\n",
+			"https://example.com/static/index": "<!DOCTYPE html><html></html>",
+			"https://example.com/static/typed": "not sniffed",
+		},
+		contentTypes: map[string]string{
+			"https://example.com/static/app":   "application/octet-stream",
+			"https://example.com/static/index": "application/octet-stream",
+			"https://example.com/static/typed": "text/javascript",
+		},
+	}
+	s := New(&config.Config{}, Options{Fetcher: fetcher})
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/static/app", true},
+		{"https://example.com/static/index", false},
+		{"https://example.com/static/typed", true},
+		{"https://example.com/static/missing", false},
+	}
+	for _, tt := range tests {
+		if got := s.probeFile(context.Background(), tt.url); got != tt.want {
+			t.Errorf("probeFile(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFileSniffedBodyIsScanned(t *testing.T) {
+	cfg := &config.Config{Rules: []config.Rule{{
+		ID:          "test-token",
+		Regex:       `token\s*=\s*"([A-Za-z0-9]{16})"`,
+		SecretGroup: 1,
+	}}}
+	fetcher := stubFetcher{
+		bodies:       map[string]string{"https://example.com/app.js": "var token = \"Zq81Xk4Lp0WmR7tY\";\n"},
+		contentTypes: map[string]string{"https://example.com/app.js": "application/octet-stream"},
+	}
+	s := New(cfg, Options{Fetcher: fetcher})
+	if err := s.checkFile(context.Background(), "https://example.com/app.js", "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if findings := s.GetFindings(); len(findings) != 1 || findings[0].Secret != "Zq81Xk4Lp0WmR7tY" {
+		t.Errorf("peeking lost the start of the body: %+v", findings)
+	}
+}
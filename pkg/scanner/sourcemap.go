@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// sourceMap is the subset of a version 3 source map needed to scan the
+// original sources it embeds
+type sourceMap struct {
+	SourceRoot string   `json:"sourceRoot"`
+	Sources    []string `json:"sources"`
+	// SourcesContent entries are null for sources the map doesn't embed
+	SourcesContent []*string `json:"sourcesContent"`
+}
+
+// isSourceMapURL checks if a target URL points directly at a source map
+func isSourceMapURL(target string) bool {
+	u, err := url.Parse(target)
+	return err == nil && strings.HasSuffix(strings.ToLower(u.Path), ".map")
+}
+
+// checkSourceMaps scans the source maps given directly as targets, which
+// need no browser
+func (s *Scanner) checkSourceMaps(ctx context.Context, mapURLs []string) {
+	for i, mapURL := range mapURLs {
+		if ctx.Err() != nil {
+			s.addUnchecked(len(mapURLs)-i, 0)
+			break
+		}
+		if s.limitReached() {
+			break
+		}
+		if err := s.checkSourceMap(ctx, mapURL); err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking source map %s: %v\n", mapURL, err)
+		}
+	}
+
+	// References found in the original sources resolve against paths that
+	// were never served, so they aren't followed
+	s.takeWorkers()
+	s.takeChunks()
+}
+
+// checkSourceMap fetches the source map at mapURL and scans each original
+// source in its sourcesContent. Findings name the source's path from the
+// map's sources array as their file and the map as their target.
+func (s *Scanner) checkSourceMap(ctx context.Context, mapURL string) (err error) {
+	report := FileReport{URL: mapURL, Status: FileScanned}
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			s.addUnchecked(1, 0)
+			report.Status = FileSkipped
+			report.Reason = fmt.Sprintf("scan stopped: %v", ctx.Err())
+			err = nil
+		}
+		if err != nil {
+			report.Status = FileError
+			report.Reason = err.Error()
+		}
+		s.recordFile(report)
+	}()
+
+	skip := func(reason string) error {
+		report.Status = FileSkipped
+		report.Reason = reason
+		return nil
+	}
+
+	if s.isIgnored(mapURL) {
+		s.debugf("Skipping ignored source map %s", mapURL)
+		return skip("matched ignore pattern")
+	}
+	if s.isExcluded(mapURL) {
+		s.debugf("Skipping source map %s not matching an include pattern", mapURL)
+		return skip("matched no include pattern")
+	}
+
+	resp, err := s.fetch(ctx, mapURL, "source map")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	report.StatusCode = resp.StatusCode
+	report.ContentType = resp.Header.Get("Content-Type")
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return skip(rateLimitedReason())
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return skip(fmt.Sprintf("unexpected status %d", resp.StatusCode))
+	}
+
+	body := io.Reader(resp.Body)
+	maxSize := s.opts.MaxFileSize
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	content, err := io.ReadAll(body)
+	report.BytesRead = int64(len(content))
+	if err != nil {
+		return fmt.Errorf("failed to read source map: %v", err)
+	}
+	if maxSize > 0 && int64(len(content)) > maxSize {
+		fmt.Fprintf(os.Stderr, "Warning: Skipping %s: size exceeds limit of %d bytes\n", mapURL, maxSize)
+		return skip(fmt.Sprintf("size exceeds limit of %d bytes", maxSize))
+	}
+	resp.Body.Close()
+
+	var sm sourceMap
+	if err := json.Unmarshal(content, &sm); err != nil {
+		return fmt.Errorf("failed to parse source map: %v", err)
+	}
+
+	scanned := 0
+	for i, source := range sm.Sources {
+		if i >= len(sm.SourcesContent) || sm.SourcesContent[i] == nil {
+			continue
+		}
+		bytes, duration := s.checkContent(sm.SourceRoot+source, mapURL, "application/javascript", []byte(*sm.SourcesContent[i]))
+		report.BytesScanned += bytes
+		report.MatchDuration += duration
+		scanned++
+	}
+	if scanned == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: Source map %s has no sourcesContent to scan\n", mapURL)
+		return skip("no sourcesContent")
+	}
+	s.debugf("Scanned %d of %d sources in %s", scanned, len(sm.Sources), mapURL)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/utils"
+)
+
+// ruleSpecificity ranks how specific the rule behind a finding is, so that
+// PreferSpecific keeps a vendor rule's finding over a generic one for the
+// same secret
+type ruleSpecificity struct {
+	// specific is false for generic rules and the object literal detector
+	specific bool
+	// keywords is true for rules that require keywords
+	keywords bool
+	// regexLength breaks ties: longer patterns describe the secret better
+	regexLength int
+}
+
+// better checks if r ranks above other
+func (r ruleSpecificity) better(other ruleSpecificity) bool {
+	if r.specific != other.specific {
+		return r.specific
+	}
+	if r.keywords != other.keywords {
+		return r.keywords
+	}
+	return r.regexLength > other.regexLength
+}
+
+// ruleSpecificities ranks the keywords and pattern of each configured rule
+// by ID, for specificity
+func (s *Scanner) ruleSpecificities() map[string]ruleSpecificity {
+	ranks := make(map[string]ruleSpecificity, len(s.config.Rules))
+	for _, rule := range s.config.Rules {
+		if _, ok := ranks[rule.ID]; !ok {
+			ranks[rule.ID] = ruleSpecificity{keywords: len(rule.Keywords) > 0, regexLength: len(rule.Regex)}
+		}
+	}
+	return ranks
+}
+
+// specificity ranks the rule that produced finding, given the ranks of the
+// configured rules
+func specificity(finding Finding, ranks map[string]ruleSpecificity) ruleSpecificity {
+	rank := ranks[finding.RuleID]
+	rank.specific = finding.RuleID != objectSecretRuleID &&
+		!strings.Contains(strings.ToLower(finding.RuleID), "generic") &&
+		!utils.Contains(finding.Tags, "generic")
+	return rank
+}
+
+// preferSpecific keeps only the most specific of the recorded findings for
+// each secret matched by several rules in the same file, in the findings'
+// order. It runs once a scan ends, as a file's findings can be recorded in
+// several batches. s.mu must be held.
+func (s *Scanner) preferSpecific() {
+	ranks := s.ruleSpecificities()
+	type key struct{ file, secret string }
+	best := make(map[key]int)
+	for i, finding := range s.findings {
+		k := key{finding.File, finding.Secret}
+		if j, ok := best[k]; !ok || specificity(finding, ranks).better(specificity(s.findings[j], ranks)) {
+			best[k] = i
+		}
+	}
+	if len(best) == len(s.findings) {
+		return
+	}
+
+	kept := make([]Finding, 0, len(best))
+	for i, finding := range s.findings {
+		if best[key{finding.File, finding.Secret}] == i {
+			kept = append(kept, finding)
+		} else {
+			s.debugf("Dropping %s finding in %s in favor of a more specific rule", finding.RuleID, finding.File)
+		}
+	}
+	s.foundFindings -= len(s.findings) - len(kept)
+	s.findings = kept
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+func TestPreferSpecific(t *testing.T) {
+	cfg := &config.Config{Rules: []config.Rule{
+		{ID: "generic-api-key", Regex: `key\s*=\s*"([A-Za-z0-9]{16})"`, SecretGroup: 1},
+		{ID: "acme-token", Regex: `acme_key\s*=\s*"([A-Za-z0-9]{16})"`, SecretGroup: 1, Keywords: []string{"acme"}},
+	}}
+	fetcher := stubFetcher{bodies: map[string]string{
+		"https://example.com/app.js":   "const acme_key = \"Zq81Xk4Lp0WmR7tY\";\n",
+		"https://example.com/other.js": "const key = \"Ab81Xk4Lp0WmR7tY\";\n",
+	}}
+	s := New(cfg, Options{Fetcher: fetcher, PreferSpecific: true})
+	for _, file := range []string{"https://example.com/app.js", "https://example.com/other.js"} {
+		if err := s.checkFile(context.Background(), file, "https://example.com/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(s.GetFindings()); got != 3 {
+		t.Fatalf("got %d findings before the scan ended, want 3", got)
+	}
+
+	s.recordRun(0, nil)
+	findings := s.GetFindings()
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if findings[0].RuleID != "acme-token" || findings[1].RuleID != "generic-api-key" {
+		t.Errorf("kept rules %s and %s, want acme-token and generic-api-key", findings[0].RuleID, findings[1].RuleID)
+	}
+	if s.Truncated() {
+		t.Error("dropping duplicates marked the findings as truncated")
+	}
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// sriTag is added to the tags of Subresource Integrity findings
+const sriTag = "sri"
+
+// sriMissingRule is the pseudo rule of third-party scripts loaded without
+// an integrity attribute
+var sriMissingRule = config.Rule{
+	ID:          "sri-missing",
+	Description: "Third-party script loaded without Subresource Integrity",
+	Tags:        []string{sriTag},
+}
+
+// sriScript returns the page's external scripts as [src, integrity,
+// crossorigin] triples, with src resolved by the browser and crossorigin
+// null when the attribute is absent
+const sriScript = `() => Array.from(document.querySelectorAll('script[src]'))
+	.map(script => [script.src, script.getAttribute('integrity') || '', script.getAttribute('crossorigin')])`
+
+// scriptTag is an external <script> of a page with its SRI attributes
+type scriptTag struct {
+	Src            string
+	Integrity      string
+	CrossOrigin    string
+	HasCrossOrigin bool
+}
+
+// String renders the tag as HTML for the context of its findings
+func (t scriptTag) String() string {
+	tag := fmt.Sprintf("<script src=%q", t.Src)
+	if t.Integrity != "" {
+		tag += fmt.Sprintf(" integrity=%q", t.Integrity)
+	}
+	if t.HasCrossOrigin {
+		tag += fmt.Sprintf(" crossorigin=%q", t.CrossOrigin)
+	}
+	return tag + ">"
+}
+
+// evaluatedScriptTags converts the result of sriScript
+func evaluatedScriptTags(result interface{}) []scriptTag {
+	values, _ := result.([]interface{})
+	var tags []scriptTag
+	for _, value := range values {
+		triple, ok := value.([]interface{})
+		if !ok || len(triple) != 3 {
+			continue
+		}
+		src, _ := triple[0].(string)
+		integrity, _ := triple[1].(string)
+		crossOrigin, hasCrossOrigin := triple[2].(string)
+		tags = append(tags, scriptTag{Src: src, Integrity: integrity, CrossOrigin: crossOrigin, HasCrossOrigin: hasCrossOrigin})
+	}
+	return tags
+}
+
+// isThirdParty checks if src is served from another site than pageURL,
+// comparing registrable domains, or hosts when either has none (such as
+// IP addresses and localhost)
+func isThirdParty(pageURL string, src string) bool {
+	pageDomain, pageErr := RegistrableDomain(pageURL)
+	srcDomain, srcErr := RegistrableDomain(src)
+	if pageErr == nil && srcErr == nil {
+		return pageDomain != srcDomain
+	}
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+	script, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	return !strings.EqualFold(page.Hostname(), script.Hostname())
+}
+
+// addScriptTags queues a finding for each third-party script of pageURL
+// without an integrity attribute, to be reported with its files. Tags'
+// srcs must already be resolved.
+func (s *Scanner) addScriptTags(pageURL string, tags []scriptTag) {
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		u, err := url.Parse(tag.Src)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		if strings.TrimSpace(tag.Integrity) != "" || seen[tag.Src] || !isThirdParty(pageURL, tag.Src) {
+			continue
+		}
+		seen[tag.Src] = true
+		findings = append(findings, Finding{
+			Description: sriMissingRule.Description,
+			File:        pageURL,
+			RuleID:      sriMissingRule.ID,
+			Tags:        sriMissingRule.Tags,
+			Secret:      tag.Src,
+			Context:     tag.String(),
+			Line:        tag.String(),
+			Severity:    SeverityLow,
+		})
+	}
+	s.debugf("Found %d third-party scripts without integrity on %s", len(findings), pageURL)
+	s.addPageFindings(findings)
+}
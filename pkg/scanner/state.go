@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// RunState records the findings of the previous run against a target host
+type RunState struct {
+	Host         string    `json:"host"`
+	LastRun      time.Time `json:"last_run"`
+	Fingerprints []string  `json:"fingerprints"`
+}
+
+// unsafeFileChars matches characters not allowed in state file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// TargetHost returns the host of a target URL, or the target itself if it
+// can't be parsed
+func TargetHost(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return target
+	}
+	return u.Host
+}
+
+// sanitizeFileName replaces characters not allowed in file names
+func sanitizeFileName(name string) string {
+	return unsafeFileChars.ReplaceAllString(name, "_")
+}
+
+// statePath returns the path of the state file for host within dir
+func statePath(dir string, host string) string {
+	return filepath.Join(dir, sanitizeFileName(host)+".json")
+}
+
+// LoadRunState loads the state of the previous run against host. On the
+// first run an empty state is returned.
+func LoadRunState(dir string, host string) (*RunState, error) {
+	data, err := os.ReadFile(statePath(dir, host))
+	if os.IsNotExist(err) {
+		return &RunState{Host: host}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run state: %v", err)
+	}
+
+	var state RunState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse run state: %v", err)
+	}
+	return &state, nil
+}
+
+// SaveRunState writes state to its host's state file within dir
+func SaveRunState(dir string, state *RunState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run state: %v", err)
+	}
+
+	if err := os.WriteFile(statePath(dir, state.Host), data, 0644); err != nil {
+		return fmt.Errorf("failed to write run state: %v", err)
+	}
+	return nil
+}
+
+// ApplySinceLast drops findings already reported in the previous run and
+// updates state to reference every finding of the current run. Only
+// findings whose target is on the state's host (or that have no target) are
+// considered. When the scan didn't cover everything (stopped early, capped
+// by MaxFindings, or sampled), the current findings are added to the
+// previous ones rather than replacing them, so findings the run didn't
+// reach aren't reported as new next time. It returns the number of
+// findings dropped.
+func (s *Scanner) ApplySinceLast(state *RunState) int {
+	previous := make(map[string]bool)
+	for _, fp := range state.Fingerprints {
+		previous[fp] = true
+	}
+
+	onHost := func(finding Finding) bool {
+		return finding.Target == "" || TargetHost(finding.Target) == state.Host
+	}
+
+	var current []string
+	for _, finding := range s.GetFindings() {
+		if onHost(finding) {
+			current = append(current, finding.Fingerprint)
+		}
+	}
+
+	removed := s.FilterFindings(func(finding Finding) bool {
+		return !onHost(finding) || !previous[finding.Fingerprint]
+	})
+
+	if s.Incomplete() {
+		for _, fp := range current {
+			if !previous[fp] {
+				previous[fp] = true
+				state.Fingerprints = append(state.Fingerprints, fp)
+			}
+		}
+	} else {
+		state.Fingerprints = current
+	}
+	state.LastRun = time.Now()
+	return removed
+}
+
+// Incomplete checks if the scan may have missed findings: a run was stopped
+// before it finished, findings were dropped beyond MaxFindings, or files
+// were sampled
+func (s *Scanner) Incomplete() bool {
+	return s.Partial() != nil || s.Truncated() || s.Sample() != nil
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import "github.com/playwright-community/playwright-go"
+
+// stealthBrowserArg stops Chromium from flagging itself as automated
+// through navigator.webdriver
+const stealthBrowserArg = "--disable-blink-features=AutomationControlled"
+
+// stealthLocale is the locale pages are opened with when Stealth is set
+const stealthLocale = "en-US"
+
+// stealthViewport is a common desktop screen size, used when Stealth is set
+// instead of Playwright's 1280x720 default
+var stealthViewport = &playwright.Size{Width: 1920, Height: 1080}
+
+// stealthScript runs before any page script when Stealth is set, hiding
+// the remaining differences between headless and regular Chrome that bot
+// checks commonly look for
+const stealthScript = `(() => {
+	Object.defineProperty(Navigator.prototype, 'webdriver', { get: () => undefined });
+	Object.defineProperty(Navigator.prototype, 'languages', { get: () => ['en-US', 'en'] });
+	if (navigator.plugins.length === 0) {
+		Object.defineProperty(Navigator.prototype, 'plugins', { get: () => [1, 2, 3, 4, 5] });
+	}
+	if (!window.chrome) {
+		window.chrome = { runtime: {} };
+	}
+	const permissions = navigator.permissions;
+	if (permissions && permissions.query) {
+		const query = permissions.query.bind(permissions);
+		permissions.query = (parameters) => parameters && parameters.name === 'notifications'
+			? Promise.resolve({ state: Notification.permission })
+			: query(parameters);
+	}
+})();`
+
+// stealthInitScript returns stealthScript as a Playwright init script
+func stealthInitScript() playwright.Script {
+	return playwright.Script{Content: playwright.String(stealthScript)}
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import "fmt"
+
+// storageItem is a localStorage or sessionStorage entry of a page
+type storageItem struct {
+	// Ref names the page, storage area, and key, such as
+	// "https://example.com/ localStorage[auth]", and is used as the file
+	// of its findings
+	Ref   string
+	Area  string
+	Key   string
+	Value string
+}
+
+// storageScript returns the entries of the page's localStorage and
+// sessionStorage as [area, key, value] triples. Storage that can't be
+// accessed, as on opaque origins, is skipped.
+const storageScript = `() => {
+	const found = [];
+	for (const area of ['localStorage', 'sessionStorage']) {
+		try {
+			const storage = window[area];
+			for (let i = 0; i < storage.length; i++) {
+				const key = storage.key(i);
+				found.push([area, key, storage.getItem(key) || '']);
+			}
+		} catch (e) {}
+	}
+	return found;
+}`
+
+// evaluatedStorage converts the result of storageScript on pageURL
+func evaluatedStorage(pageURL string, result interface{}) []storageItem {
+	values, _ := result.([]interface{})
+	var items []storageItem
+	for _, value := range values {
+		triple, ok := value.([]interface{})
+		if !ok || len(triple) != 3 {
+			continue
+		}
+		area, _ := triple[0].(string)
+		key, _ := triple[1].(string)
+		itemValue, _ := triple[2].(string)
+		if itemValue == "" {
+			continue
+		}
+		items = append(items, storageItem{
+			Ref:   fmt.Sprintf("%s %s[%s]", pageURL, area, key),
+			Area:  area,
+			Key:   key,
+			Value: itemValue,
+		})
+	}
+	return items
+}
+
+// addStorage queues storage entries found on a page to be scanned with its
+// files
+func (s *Scanner) addStorage(items []storageItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pendingStorage = append(s.pendingStorage, items...)
+}
+
+// checkStorage scans the queued storage entries for secrets, attributing
+// findings to target. Each entry is matched as "key: value" so rules
+// keyed on the name see it, and its findings' context is the storage key.
+func (s *Scanner) checkStorage(target string) {
+	s.mu.Lock()
+	items := s.pendingStorage
+	s.pendingStorage = nil
+	s.mu.Unlock()
+
+	if len(items) > 0 {
+		s.debugf("Scanning %d storage entries", len(items))
+	}
+	for _, item := range items {
+		if s.limitReached() {
+			return
+		}
+		content := item.Key + ": " + item.Value
+		findings := s.findSecrets(item.Ref, content)
+		for i := range findings {
+			findings[i].Context = item.Area + "." + item.Key
+			findings[i].Target = target
+		}
+		s.addFindings(findings)
+	}
+}
@@ -0,0 +1,245 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// defaultCTLogURL is the crt.sh search queried for subdomains when
+// ScanOptions.CTLogURL isn't set
+const defaultCTLogURL = "https://crt.sh/"
+
+// ctLogTimeout bounds the certificate transparency query, which can be
+// slow for large domains
+const ctLogTimeout = 60 * time.Second
+
+// subdomainProbeTimeout bounds each check of whether a subdomain is live
+const subdomainProbeTimeout = 10 * time.Second
+
+// subdomainTag marks findings from a subdomain found by CTSubdomains,
+// alongside a "subdomain:<host>" tag naming it
+const subdomainTag = "subdomain"
+
+// ctEntry is one certificate in a crt.sh JSON response. NameValue holds
+// the certificate's names separated by newlines.
+type ctEntry struct {
+	CommonName string `json:"common_name"`
+	NameValue  string `json:"name_value"`
+}
+
+// RegistrableDomain returns the domain of target's host one label below
+// its public suffix, such as example.co.uk for https://app.example.co.uk/
+func RegistrableDomain(target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Hostname() == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidURL, target)
+	}
+	return publicsuffix.EffectiveTLDPlusOne(strings.ToLower(u.Hostname()))
+}
+
+// CTSubdomains queries certificate transparency logs at ctLogURL (crt.sh
+// when empty) for the names under domain, returning the distinct hosts
+// sorted, including domain itself. Wildcard names are reduced to the
+// domain they cover.
+func (s *Scanner) CTSubdomains(ctx context.Context, ctLogURL string, domain string) ([]string, error) {
+	if ctLogURL == "" {
+		ctLogURL = defaultCTLogURL
+	}
+	query, err := url.Parse(ctLogURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CT log URL: %v", err)
+	}
+	query.RawQuery = url.Values{"q": {"%." + domain}, "output": {"json"}}.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, ctLogTimeout)
+	defer cancel()
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	// The scan's headers and cookies belong to the target, not the log
+	req, err := http.NewRequestWithContext(ctx, "GET", query.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("User-Agent", s.userAgent())
+	resp, err := s.fetcher.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query certificate transparency logs: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to query certificate transparency logs: status %d", resp.StatusCode)
+	}
+
+	var entries []ctEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to parse certificate transparency results: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, entry := range entries {
+		for _, name := range strings.Fields(entry.NameValue + "\n" + entry.CommonName) {
+			name = strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(name), "*."), ".")
+			if name != domain && !strings.HasSuffix(name, "."+domain) {
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				hosts = append(hosts, name)
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// subdomainPages adds the live subdomains of each target's registrable
+// domain, found in certificate transparency logs, to targets, recording
+// them so their findings are tagged. Subdomains take their target's scheme
+// and are probed with up to concurrency workers. At most maxHosts hosts are
+// probed beyond those needed and scanned in total (0 for no limit).
+func (s *Scanner) subdomainPages(ctx context.Context, targets []string, ctLogURL string, maxHosts int, concurrency int) []string {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	if s.subdomainTargets == nil {
+		s.subdomainTargets = make(map[string]bool)
+	}
+	hosts := make(map[string]bool)
+	for _, target := range targets {
+		hosts[TargetHost(target)] = true
+	}
+	full := func() bool { return maxHosts > 0 && len(hosts) >= maxHosts }
+
+	queried := make(map[string]bool)
+	pages := append([]string{}, targets...)
+	for _, target := range targets {
+		if ctx.Err() != nil || full() {
+			break
+		}
+		domain, err := RegistrableDomain(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Skipping subdomain enumeration for %s: %v\n", target, err)
+			continue
+		}
+		if queried[domain] {
+			continue
+		}
+		queried[domain] = true
+
+		names, err := s.CTSubdomains(ctx, ctLogURL, domain)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to enumerate subdomains of %s: %v\n", domain, err)
+			continue
+		}
+		scheme := "https"
+		if u, err := url.Parse(target); err == nil {
+			scheme = u.Scheme
+		}
+		var candidates []string
+		for _, name := range names {
+			if !hosts[name] {
+				candidates = append(candidates, scheme+"://"+name+"/")
+			}
+		}
+
+		// Probe no more candidates than there are hosts left to scan, then
+		// the next ones in place of any that weren't live
+		live := 0
+		for len(candidates) > 0 && !full() && ctx.Err() == nil {
+			batch := candidates
+			if maxHosts > 0 && len(batch) > maxHosts-len(hosts) {
+				batch = batch[:maxHosts-len(hosts)]
+			}
+			candidates = candidates[len(batch):]
+			for _, page := range s.probeHosts(ctx, batch, concurrency) {
+				hosts[TargetHost(page)] = true
+				s.subdomainTargets[page] = true
+				pages = append(pages, page)
+				live++
+			}
+		}
+		s.debugf("Found %d subdomains of %s, %d live", len(names), domain, live)
+		if len(candidates) > 0 && full() {
+			fmt.Fprintf(os.Stderr, "Warning: Reached the limit of %d hosts, skipping the remaining subdomains of %s\n", maxHosts, domain)
+		}
+	}
+	return pages
+}
+
+// tagSubdomain tags a finding from a subdomain found by subdomainPages
+// with subdomainTag and the subdomain's host
+func (s *Scanner) tagSubdomain(finding *Finding) {
+	if s.subdomainTargets[finding.Target] {
+		finding.Tags = append(append([]string{}, finding.Tags...), subdomainTag, subdomainTag+":"+TargetHost(finding.Target))
+	}
+}
+
+// probeHosts returns the pages in candidates that respond at all, in
+// order, checking up to concurrency at once
+func (s *Scanner) probeHosts(ctx context.Context, candidates []string, concurrency int) []string {
+	live := make([]bool, len(candidates))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				live[i] = s.probeHost(ctx, candidates[i])
+			}
+		}()
+	}
+	for i := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var pages []string
+	for i, candidate := range candidates {
+		if live[i] {
+			pages = append(pages, candidate)
+		}
+	}
+	return pages
+}
+
+// probeHost checks if pageURL's host answers HTTP requests
+func (s *Scanner) probeHost(ctx context.Context, pageURL string) bool {
+	ctx, cancel := context.WithTimeout(ctx, subdomainProbeTimeout)
+	defer cancel()
+	if err := s.limiter.Wait(ctx); err != nil {
+		return false
+	}
+	release := s.acquireHost(pageURL)
+	defer release()
+
+	req, err := s.newRequest(ctx, pageURL)
+	if err != nil {
+		return false
+	}
+	resp, err := s.fetcher.Do(req)
+	if err != nil {
+		s.debugf("Skipping %s: %v", pageURL, err)
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+	"github.com/nautical/jsweb/pkg/utils"
+)
+
+// probeFetcher answers the CT log query with ct and every other request
+// with a 200, except for hosts in dead, counting the probes
+type probeFetcher struct {
+	ct     stubFetcher
+	dead   map[string]bool
+	mu     sync.Mutex
+	probes int
+}
+
+func (f *probeFetcher) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == "ct.test" {
+		return f.ct.Do(req)
+	}
+	f.mu.Lock()
+	f.probes++
+	f.mu.Unlock()
+	if f.dead[req.URL.Host] {
+		return nil, errors.New("connection refused")
+	}
+	return stubFetcher{bodies: map[string]string{req.URL.String(): ""}}.Do(req)
+}
+
+func TestSubdomainPagesMaxHosts(t *testing.T) {
+	fetcher := &probeFetcher{
+		ct: stubFetcher{bodies: map[string]string{
+			"https://ct.test/?output=json&q=%25.example.com": `[{"name_value":"a.example.com\nb.example.com\nc.example.com"},{"common_name":"d.example.com","name_value":"*.e.example.com"}]`,
+		}},
+		dead: map[string]bool{"a.example.com": true},
+	}
+	s := New(&config.Config{}, Options{Fetcher: fetcher})
+
+	pages := s.subdomainPages(context.Background(), []string{"https://example.com/"}, "https://ct.test/", 3, 1)
+	want := []string{"https://example.com/", "https://b.example.com/", "https://c.example.com/"}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("subdomainPages() = %v, want %v", pages, want)
+	}
+	if fetcher.probes != 3 {
+		t.Errorf("probed %d subdomains, want 3", fetcher.probes)
+	}
+
+	findings := []Finding{
+		{RuleID: "test", Target: "https://b.example.com/", Tags: []string{"generic"}},
+		{RuleID: "test", Target: "https://example.com/"},
+	}
+	s.addFindings(findings)
+	got := s.GetFindings()
+	if len(got) != 2 {
+		t.Fatalf("got %d findings, want 2", len(got))
+	}
+	if want := []string{"generic", "subdomain", "subdomain:b.example.com"}; !reflect.DeepEqual(got[0].Tags, want) {
+		t.Errorf("subdomain finding tags = %v, want %v", got[0].Tags, want)
+	}
+	if utils.Contains(got[1].Tags, subdomainTag) {
+		t.Errorf("target finding was tagged %v", got[1].Tags)
+	}
+}
@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// slowestFiles is how many files Summarize lists by matching time
+const slowestFiles = 5
+
+// RuleSummary holds the finding counts for a single rule
+type RuleSummary struct {
+	RuleID   string `json:"rule_id"`
+	Findings int    `json:"findings"`
+	Files    int    `json:"files"`
+}
+
+// FileSummary holds the finding count for a single file
+type FileSummary struct {
+	File     string `json:"file"`
+	Findings int    `json:"findings"`
+}
+
+// FileTiming holds the size and matching time of a single file
+type FileTiming struct {
+	File          string        `json:"file"`
+	BytesScanned  int64         `json:"bytes_scanned"`
+	MatchDuration time.Duration `json:"match_duration"`
+}
+
+// Summary aggregates findings by rule and by file, and lists the files that
+// took longest to match
+type Summary struct {
+	TotalFindings int           `json:"total_findings"`
+	TotalFiles    int           `json:"total_files"`
+	ByRule        []RuleSummary `json:"by_rule"`
+	ByFile        []FileSummary `json:"by_file"`
+	SlowestFiles  []FileTiming  `json:"slowest_files"`
+}
+
+// Summarize aggregates the current findings by rule ID and by file
+func (s *Scanner) Summarize() Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ruleCounts := make(map[string]int)
+	ruleFiles := make(map[string]map[string]bool)
+	fileCounts := make(map[string]int)
+
+	for _, finding := range s.findings {
+		ruleCounts[finding.RuleID]++
+		if ruleFiles[finding.RuleID] == nil {
+			ruleFiles[finding.RuleID] = make(map[string]bool)
+		}
+		ruleFiles[finding.RuleID][finding.File] = true
+		fileCounts[finding.File]++
+	}
+
+	summary := Summary{
+		TotalFindings: len(s.findings),
+		TotalFiles:    len(fileCounts),
+	}
+
+	for ruleID, count := range ruleCounts {
+		summary.ByRule = append(summary.ByRule, RuleSummary{
+			RuleID:   ruleID,
+			Findings: count,
+			Files:    len(ruleFiles[ruleID]),
+		})
+	}
+	for file, count := range fileCounts {
+		summary.ByFile = append(summary.ByFile, FileSummary{File: file, Findings: count})
+	}
+
+	for _, file := range s.fileReports {
+		if file.MatchDuration > 0 {
+			summary.SlowestFiles = append(summary.SlowestFiles, FileTiming{
+				File:          file.URL,
+				BytesScanned:  file.BytesScanned,
+				MatchDuration: file.MatchDuration,
+			})
+		}
+	}
+	sort.Slice(summary.SlowestFiles, func(i, j int) bool {
+		return summary.SlowestFiles[i].MatchDuration > summary.SlowestFiles[j].MatchDuration
+	})
+	if len(summary.SlowestFiles) > slowestFiles {
+		summary.SlowestFiles = summary.SlowestFiles[:slowestFiles]
+	}
+
+	// Most frequent first, then alphabetically for stable output
+	sort.Slice(summary.ByRule, func(i, j int) bool {
+		if summary.ByRule[i].Findings != summary.ByRule[j].Findings {
+			return summary.ByRule[i].Findings > summary.ByRule[j].Findings
+		}
+		return summary.ByRule[i].RuleID < summary.ByRule[j].RuleID
+	})
+	sort.Slice(summary.ByFile, func(i, j int) bool {
+		if summary.ByFile[i].Findings != summary.ByFile[j].Findings {
+			return summary.ByFile[i].Findings > summary.ByFile[j].Findings
+		}
+		return summary.ByFile[i].File < summary.ByFile[j].File
+	})
+
+	return summary
+}
+
+// PrintSummary writes a human-readable summary of the findings to w
+func (s *Scanner) PrintSummary(w io.Writer) error {
+	summary := s.Summarize()
+
+	lines := []string{"", "Summary:", "  By rule:"}
+	for _, rule := range summary.ByRule {
+		lines = append(lines, fmt.Sprintf("    %s: %d %s across %d %s",
+			rule.RuleID, rule.Findings, plural(rule.Findings, "finding"), rule.Files, plural(rule.Files, "file")))
+	}
+
+	lines = append(lines, "  By file:")
+	for _, file := range summary.ByFile {
+		lines = append(lines, fmt.Sprintf("    %s: %d %s", file.File, file.Findings, plural(file.Findings, "finding")))
+	}
+
+	lines = append(lines, fmt.Sprintf("  Total: %d %s across %d %s",
+		summary.TotalFindings, plural(summary.TotalFindings, "finding"), summary.TotalFiles, plural(summary.TotalFiles, "file")))
+
+	if len(summary.SlowestFiles) > 0 {
+		lines = append(lines, "  Slowest to match:")
+		for _, file := range summary.SlowestFiles {
+			lines = append(lines, fmt.Sprintf("    %s: %.1f KB in %s",
+				file.File, float64(file.BytesScanned)/1e3, file.MatchDuration.Round(time.Microsecond)))
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write summary: %v", err)
+		}
+	}
+	return nil
+}
+
+// plural returns word with an "s" suffix unless count is one
+func plural(count int, word string) string {
+	if count == 1 {
+		return word
+	}
+	return word + "s"
+}
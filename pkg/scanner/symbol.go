@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// symbolLookback is how many bytes before a secret are searched for the
+// symbol it's defined in
+const symbolLookback = 1000
+
+// symbolRegex matches the declarations a secret can live in: a named
+// function, a const/let/var assignment, or an object property
+var symbolRegex = regexp.MustCompile(`function\s*\*?\s*([A-Za-z_$][\w$]*)\s*\(|\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=|["']?([A-Za-z_$][\w$]*)["']?\s*:`)
+
+// nonSymbols are words followed by a colon that don't name a property
+var nonSymbols = map[string]bool{
+	"case":    true,
+	"default": true,
+	"http":    true,
+	"https":   true,
+	"data":    true,
+	"blob":    true,
+}
+
+// enclosingSymbol returns the name of the function, variable, or property
+// nearest before offset in content, or "" if none is found. It's a
+// lexical heuristic, not a parser, so it can pick the wrong name in
+// unusual code.
+func enclosingSymbol(content string, offset int) string {
+	start := offset - symbolLookback
+	if start < 0 {
+		start = 0
+	}
+	before := content[start:offset]
+
+	matches := symbolRegex.FindAllStringSubmatchIndex(before, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		match := matches[i]
+		for group := 1; group <= 3; group++ {
+			if match[2*group] < 0 {
+				continue
+			}
+			name := before[match[2*group]:match[2*group+1]]
+			// A property must be followed by its value, not "::" or the
+			// "//" of a URL
+			if group == 3 && (nonSymbols[strings.ToLower(name)] ||
+				strings.HasPrefix(before[match[1]:], ":") || strings.HasPrefix(before[match[1]:], "//")) {
+				break
+			}
+			return name
+		}
+	}
+	return ""
+}
+
+// setEnclosingSymbols sets the symbol each finding's secret is defined in
+func setEnclosingSymbols(content string, findings []Finding) {
+	for i := range findings {
+		pos := findingOffset(content, findings[i])
+		if pos < 0 {
+			continue
+		}
+		// Start from the secret when it's within the matched line
+		if j := strings.Index(content[pos:], findings[i].Secret); findings[i].Secret != "" && j >= 0 && j <= len(findings[i].Line) {
+			pos += j
+		}
+		findings[i].EnclosingSymbol = enclosingSymbol(content, pos)
+	}
+}
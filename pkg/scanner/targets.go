@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileTarget is a JavaScript file URL to fetch and scan directly, with
+// request headers merged over the scanner's own
+type FileTarget struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// LoadFileTargets reads a JSON array of FileTargets, such as
+// [{"url":"https://example.com/app.js","headers":{"Authorization":"..."}}],
+// checking that each URL is an http(s) URL
+func LoadFileTargets(path string) ([]FileTarget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %v", err)
+	}
+	var targets []FileTarget
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("failed to parse targets file %s: %v", path, err)
+	}
+	for _, target := range targets {
+		if u, err := url.Parse(target.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: %q in %s", ErrInvalidURL, target.URL, path)
+		}
+	}
+	return targets, nil
+}
+
+// ScanFiles fetches and scans each file in targets with up to concurrency
+// workers, without a browser or page discovery. Each file is its findings'
+// target, and chunks or workers the files reference aren't followed.
+func (s *Scanner) ScanFiles(ctx context.Context, targets []FileTarget, concurrency int) error {
+	if s.optionsErr != nil {
+		return s.optionsErr
+	}
+
+	start := time.Now()
+	err := s.scanFiles(ctx, targets, concurrency)
+	s.recordRun(time.Since(start), err)
+	return err
+}
+
+// scanFiles performs the scan for ScanFiles
+func (s *Scanner) scanFiles(ctx context.Context, targets []FileTarget, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	s.fileHeaders = make(map[string]map[string]string)
+	var files []string
+	for _, target := range targets {
+		if len(target.Headers) > 0 {
+			s.fileHeaders[target.URL] = target.Headers
+		}
+		files = append(files, target.URL)
+	}
+	s.markSeen(files)
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				if err := s.checkFile(ctx, file, file); err != nil {
+					fmt.Fprintf(os.Stderr, "Error checking file %s: %v\n", file, err)
+				}
+			}
+		}()
+	}
+	for i, file := range files {
+		if ctx.Err() != nil {
+			s.addUnchecked(len(files)-i, 0)
+			break
+		}
+		if s.limitReached() {
+			break
+		}
+		jobs <- file
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Only the listed files are scanned
+	s.takeWorkers()
+	s.takeChunks()
+	return ctx.Err()
+}
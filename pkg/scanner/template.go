@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFormat is the output format that renders reports through
+// ReportInfo.Template
+const TemplateFormat = "template"
+
+// TemplateData is what a report template is executed with
+type TemplateData struct {
+	Targets   []string
+	Version   string
+	Timestamp time.Time
+	// ConfigHash is the hash of the configuration the rules came from
+	ConfigHash string
+	Findings   []Finding
+	// Skipped, Endpoints and Files are filled in when the matching options
+	// are enabled, as in the JSON report
+	Skipped   []FileReport
+	Endpoints []Endpoint
+	Files     []FileReport
+	// TotalFindings is the number of findings discovered when Findings was
+	// truncated to MaxFindings, and 0 otherwise
+	TotalFindings int
+	// Sample describes the files checked by a scan sampled with
+	// --sample-rate, and is nil otherwise
+	Sample *SampleInfo
+	// Partial describes how far a scan stopped by --deadline got, and is
+	// nil otherwise
+	Partial *PartialInfo
+}
+
+// templateFuncs are the functions available to report templates besides
+// the text/template built-ins
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"redact": redactSecret,
+	"join":   strings.Join,
+	"upper":  strings.ToUpper,
+	"lower":  strings.ToLower,
+}
+
+// LoadTemplate parses the text/template report template at path
+func LoadTemplate(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %v", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate renders data through info.Template
+func writeTemplate(w io.Writer, data reportData, info ReportInfo) error {
+	if info.Template == nil {
+		return fmt.Errorf("the %s format requires a template", TemplateFormat)
+	}
+	err := info.Template.Execute(w, TemplateData{
+		Targets:       info.Targets,
+		Version:       info.Version,
+		Timestamp:     info.Timestamp,
+		ConfigHash:    info.ConfigHash,
+		Findings:      data.Findings,
+		Skipped:       data.Skipped,
+		Endpoints:     data.Endpoints,
+		Files:         data.Files,
+		TotalFindings: data.TotalFindings,
+		Sample:        data.Sample,
+		Partial:       data.Partial,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render template: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+// Cl de l'API  socit
+var apiToken = "Zq81Xk4Lp0WmR7tY"; // dj utilis
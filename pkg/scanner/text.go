@@ -0,0 +1,180 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ANSI escape sequences used by the text format
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiDim     = "\033[2m"
+	ansiRed     = "\033[31m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+	ansiBlue    = "\033[34m"
+	ansiBoldRed = "\033[1;31m"
+	ansiInverse = "\033[7m"
+)
+
+// severityColors maps each severity to the color it's printed in
+var severityColors = map[string]string{
+	SeverityCritical: ansiBoldRed,
+	SeverityHigh:     ansiRed,
+	SeverityMedium:   ansiYellow,
+	SeverityLow:      ansiBlue,
+}
+
+// textWriter writes human-readable findings, colorized if color is set
+type textWriter struct {
+	w     io.Writer
+	color bool
+	err   error
+}
+
+// paint wraps s in the ANSI sequence code when color is enabled
+func (t *textWriter) paint(code string, s string) string {
+	if !t.color || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// printf writes to the underlying writer, keeping the first error
+func (t *textWriter) printf(format string, args ...interface{}) {
+	if t.err == nil {
+		_, t.err = fmt.Fprintf(t.w, format, args...)
+	}
+}
+
+// writeFinding prints a finding's severity, rule, redacted secret,
+// location, and snippet with the secret highlighted
+func (t *textWriter) writeFinding(finding Finding) {
+	severity := finding.Severity
+	if severity == "" {
+		severity = "finding"
+	}
+	t.printf("%s %s %s\n",
+		t.paint(severityColors[finding.Severity], "["+strings.ToUpper(severity)+"]"),
+		t.paint(ansiBold, finding.Description),
+		t.paint(ansiDim, "("+finding.RuleID+")"))
+
+	redacted := redactSecret(finding.Secret)
+	t.printf("  Secret:  %s\n", redacted)
+	file := finding.File
+	if finding.LineNumber > 0 {
+		file = fmt.Sprintf("%s:%d", file, finding.LineNumber)
+	}
+	t.printf("  File:    %s\n", file)
+	if finding.Target != "" && finding.Target != finding.File {
+		t.printf("  Target:  %s\n", finding.Target)
+	}
+	if len(finding.Tags) > 0 {
+		t.printf("  Tags:    %s\n", strings.Join(finding.Tags, ", "))
+	}
+	if finding.Entropy > 0 {
+		t.printf("  Entropy: %.2f\n", finding.Entropy)
+	}
+	if finding.ContextIdentifier != "" {
+		t.printf("  Context: assigned to %s (confidence %.1f)\n", finding.ContextIdentifier, finding.ContextConfidence)
+	}
+	if finding.EnclosingSymbol != "" {
+		t.printf("  Symbol:  %s\n", finding.EnclosingSymbol)
+	}
+
+	snippet := strings.TrimSpace(finding.CodeSnippet)
+	if snippet == "" {
+		t.printf("\n")
+		return
+	}
+	if finding.Secret != "" {
+		snippet = strings.ReplaceAll(snippet, finding.Secret, t.paint(ansiInverse, redacted))
+	}
+	for _, line := range strings.Split(snippet, "\n") {
+		t.printf("    %s\n", line)
+	}
+	t.printf("\n")
+}
+
+// writeFindings prints findings, or a note that there are none
+func (t *textWriter) writeFindings(findings []Finding) {
+	if len(findings) == 0 {
+		t.printf("%s\n\n", t.paint(ansiGreen, "No findings."))
+		return
+	}
+	for _, finding := range findings {
+		t.writeFinding(finding)
+	}
+}
+
+// heading prints a section title
+func (t *textWriter) heading(title string) {
+	t.printf("%s\n\n", t.paint(ansiBold, title))
+}
+
+// writeText writes findings (and any skipped files and endpoints) for
+// reading in a terminal
+func writeText(w io.Writer, data reportData, info ReportInfo) error {
+	t := &textWriter{w: w, color: info.Color}
+	t.writeFindings(data.Findings)
+
+	if len(data.Skipped) > 0 {
+		t.heading("Skipped files")
+		for _, report := range data.Skipped {
+			reason := report.Status
+			if report.Reason != "" {
+				reason += ": " + report.Reason
+			}
+			t.printf("  %s (%s)\n", report.URL, reason)
+		}
+		t.printf("\n")
+	}
+
+	if len(data.Endpoints) > 0 {
+		t.heading("Endpoints")
+		for _, endpoint := range data.Endpoints {
+			t.printf("  %-8s %s\n", endpoint.Kind, endpoint.Value)
+		}
+		t.printf("\n")
+	}
+
+	summary := fmt.Sprintf("%d findings", len(data.Findings))
+	if len(data.Findings) == 1 {
+		summary = "1 finding"
+	}
+	if data.TotalFindings > 0 {
+		summary += fmt.Sprintf(" (report truncated, %d discovered)", data.TotalFindings)
+	}
+	if data.Sample != nil {
+		summary += fmt.Sprintf(" (sampled at rate %g, %d of %d files)", data.Sample.Rate, data.Sample.SampledFiles, data.Sample.TotalFiles)
+	}
+	if data.Partial != nil {
+		summary += fmt.Sprintf(" (%s)", data.Partial)
+	}
+	t.printf("%s\n", t.paint(ansiBold, summary))
+
+	if t.err != nil {
+		return fmt.Errorf("failed to write findings: %v", t.err)
+	}
+	return nil
+}
+
+// writeTextDiff writes the added, removed, and unchanged findings of a diff
+// for reading in a terminal
+func writeTextDiff(w io.Writer, diff DiffResult, info ReportInfo) error {
+	t := &textWriter{w: w, color: info.Color}
+	for _, group := range []struct {
+		title    string
+		findings []Finding
+	}{{"Added", diff.Added}, {"Removed", diff.Removed}, {"Unchanged", diff.Unchanged}} {
+		t.heading(fmt.Sprintf("%s (%d)", group.title, len(group.findings)))
+		t.writeFindings(group.findings)
+	}
+
+	if t.err != nil {
+		return fmt.Errorf("failed to write diff: %v", t.err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// tlsVersions names the TLS versions accepted by ParseTLSVersion, as they
+// appear in FileReport.TLSVersion
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "1.0",
+	tls.VersionTLS11: "1.1",
+	tls.VersionTLS12: "1.2",
+	tls.VersionTLS13: "1.3",
+}
+
+// chromiumTLSVersions are the --ssl-version-min values Chromium accepts
+var chromiumTLSVersions = map[uint16]string{
+	tls.VersionTLS12: "tls1.2",
+	tls.VersionTLS13: "tls1.3",
+}
+
+// ParseTLSVersion parses a TLS version such as 1.2 or 1.3
+func ParseTLSVersion(version string) (uint16, error) {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "tls")
+	for id, name := range tlsVersions {
+		if name == version {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q (supported: 1.0, 1.1, 1.2, 1.3)", version)
+}
+
+// ParseCipherSuites parses a comma-separated list of TLS 1.2 cipher suite
+// names, such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Insecure suites
+// are refused.
+func ParseCipherSuites(list string) ([]uint16, error) {
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// tlsConfig returns the TLS settings of file fetches, or nil for Go's
+// defaults
+func tlsConfig(opts Options) *tls.Config {
+	if opts.MinTLSVersion == 0 && len(opts.CipherSuites) == 0 {
+		return nil
+	}
+	return &tls.Config{
+		MinVersion:   opts.MinTLSVersion,
+		CipherSuites: opts.CipherSuites,
+	}
+}
+
+// tlsVersionName returns the version of a negotiated TLS connection
+func tlsVersionName(state *tls.ConnectionState) string {
+	if state == nil {
+		return ""
+	}
+	if name, ok := tlsVersions[state.Version]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04x", state.Version)
+}
@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// tracePath returns the file the nth trace of a run is written to: Trace
+// itself for the first, then numbered like trace-2.zip
+func (s *Scanner) tracePath(n int) string {
+	if n == 1 {
+		return s.opts.Trace
+	}
+	ext := filepath.Ext(s.opts.Trace)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(s.opts.Trace, ext), n, ext)
+}
+
+// startTrace starts a Playwright trace of page's context if Options.Trace
+// is set, and returns a function saving it that must run before the page
+// is closed. A trace that fails to start or save only warns.
+func (s *Scanner) startTrace(page playwright.Page, pageURL string) func() {
+	if s.opts.Trace == "" {
+		return func() {}
+	}
+
+	tracing := page.Context().Tracing()
+	err := tracing.Start(playwright.TracingStartOptions{
+		Title:       playwright.String(pageURL),
+		Screenshots: playwright.Bool(true),
+		Snapshots:   playwright.Bool(true),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to start tracing %s: %v\n", pageURL, err)
+		return func() {}
+	}
+
+	return func() {
+		s.mu.Lock()
+		s.traces++
+		path := s.tracePath(s.traces)
+		s.mu.Unlock()
+
+		if err := tracing.Stop(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to save the trace of %s: %v\n", pageURL, err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Saved the trace of %s to %s\n", pageURL, path)
+	}
+}
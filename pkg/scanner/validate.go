@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/utils"
+)
+
+// waitStates are the load states WaitUntil accepts
+var waitStates = []string{"load", "domcontentloaded", "networkidle", "commit"}
+
+// Validate checks the options for values a scan can't use, such as an
+// unknown WaitUntil state or a WindowOverlap above half the ScanWindow.
+// The error wraps ErrInvalidOptions. Scans run by a Scanner created with
+// invalid options fail with it.
+func (o Options) Validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidOptions, fmt.Sprintf(format, args...))
+	}
+
+	if o.WaitUntil != "" && !utils.Contains(waitStates, o.WaitUntil) {
+		return invalid("unsupported WaitUntil %q (supported: %s)", o.WaitUntil, strings.Join(waitStates, ", "))
+	}
+	if o.EntropyMode != "" && o.EntropyMode != EntropyRune && o.EntropyMode != EntropyByte {
+		return invalid("unsupported EntropyMode %q (supported: %s, %s)", o.EntropyMode, EntropyRune, EntropyByte)
+	}
+	if o.MinSeverity != "" && !IsValidSeverity(o.MinSeverity) {
+		return invalid("unsupported MinSeverity %q (supported: %s)", o.MinSeverity, strings.Join(Severities, ", "))
+	}
+
+	if o.ScanWindow < 0 || o.WindowOverlap < 0 {
+		return invalid("ScanWindow and WindowOverlap must not be negative")
+	}
+	if o.ScanWindow > 0 && int64(o.WindowOverlap)*2 > o.ScanWindow {
+		return invalid("WindowOverlap must be at most half of ScanWindow")
+	}
+	if o.ScrollSteps < 0 {
+		return invalid("ScrollSteps must not be negative")
+	}
+	if o.SnippetCharsBefore < 0 || o.SnippetCharsAfter < 0 {
+		return invalid("SnippetCharsBefore and SnippetCharsAfter must not be negative")
+	}
+	if o.EntropyZScore < 0 {
+		return invalid("EntropyZScore must not be negative")
+	}
+	if o.SampleRate < 0 || o.SampleRate > 1 {
+		return invalid("SampleRate must be between 0 and 1")
+	}
+	if o.Rate < 0 {
+		return invalid("Rate must not be negative")
+	}
+	if o.StopOnLimit && o.MaxFindings <= 0 {
+		return invalid("StopOnLimit requires MaxFindings")
+	}
+
+	if o.VerifyWebhook != "" {
+		if u, err := url.Parse(o.VerifyWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalid("VerifyWebhook must be an http or https URL")
+		}
+	}
+
+	if o.NoBrowser && (o.LoginScript != nil || o.UserDataDir != "" || o.ScanStorage || o.RuntimeScan || o.Scroll || o.Trace != "" || o.RetryNavigation) {
+		return invalid("NoBrowser can't be combined with LoginScript, UserDataDir, ScanStorage, RuntimeScan, Scroll, Trace, or RetryNavigation")
+	}
+	return nil
+}
+
+// Validate checks the run options for values a scan can't use. The error
+// wraps ErrInvalidOptions.
+func (o RunOptions) Validate() error {
+	if o.MaxPages < 0 || o.MaxHosts < 0 {
+		return fmt.Errorf("%w: MaxPages and MaxHosts must not be negative", ErrInvalidOptions)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		valid bool
+	}{
+		{"zero options", Options{}, true},
+		{"overlap within half the window", Options{ScanWindow: 1000, WindowOverlap: 500}, true},
+		{"overlap above half the window", Options{ScanWindow: 1000, WindowOverlap: 501}, false},
+		{"unknown wait state", Options{WaitUntil: "idle"}, false},
+		{"unknown entropy mode", Options{EntropyMode: "bit"}, false},
+		{"sample rate above 1", Options{SampleRate: 1.5}, false},
+		{"negative snippet", Options{SnippetCharsBefore: -1}, false},
+		{"negative scroll steps", Options{ScrollSteps: -1}, false},
+		{"stop on limit without a limit", Options{StopOnLimit: true}, false},
+		{"webhook without a scheme", Options{VerifyWebhook: "example.com/hook"}, false},
+		{"no browser with scroll", Options{NoBrowser: true, Scroll: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Validate() = %v, want ErrInvalidOptions", err)
+			}
+		})
+	}
+}
+
+func TestScanRejectsInvalidOptions(t *testing.T) {
+	s := New(&config.Config{}, Options{ScanWindow: 100, WindowOverlap: 80})
+	if err := s.ScanFiles(context.Background(), nil, 1); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("ScanFiles() = %v, want ErrInvalidOptions", err)
+	}
+}
@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// verifyTimeout bounds each call to the verification webhook
+const verifyTimeout = 10 * time.Second
+
+// Verification outcomes recorded on findings checked by VerifyWebhook
+const (
+	VerificationAccepted = "accepted"
+	// VerificationUnverified findings are kept because the webhook failed
+	VerificationUnverified = "unverified"
+)
+
+// verifyRequest is the JSON body POSTed to the verification webhook for
+// each finding
+type verifyRequest struct {
+	RuleID      string `json:"rule_id"`
+	File        string `json:"file"`
+	Target      string `json:"target,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+	// SecretHash is the hex SHA-256 of the secret, a one-way token the
+	// service can compare against secrets it knows
+	SecretHash     string `json:"secret_hash"`
+	RedactedSecret string `json:"redacted_secret"`
+	// Secret is only sent with VerifySecrets
+	Secret string `json:"secret,omitempty"`
+}
+
+// verifyResponse is the verification webhook's verdict on a finding
+type verifyResponse struct {
+	Accept bool   `json:"accept"`
+	Reason string `json:"reason"`
+}
+
+// verifyFindings asks VerifyWebhook about each finding and returns those it
+// accepts. Findings are kept, marked unverified, when the webhook fails.
+func (s *Scanner) verifyFindings(findings []Finding) []Finding {
+	kept := findings[:0]
+	for _, finding := range findings {
+		accept, err := s.verifyFinding(finding)
+		switch {
+		case err != nil:
+			s.verifyWarning.Do(func() {
+				fmt.Fprintf(os.Stderr, "Warning: Verification webhook failed, keeping unverified findings: %v\n", err)
+			})
+			finding.Verification = fmt.Sprintf("%s: %v", VerificationUnverified, err)
+		case !accept:
+			continue
+		default:
+			finding.Verification = VerificationAccepted
+		}
+		kept = append(kept, finding)
+	}
+	return kept
+}
+
+// verifyFinding POSTs a finding to VerifyWebhook and returns its verdict
+func (s *Scanner) verifyFinding(finding Finding) (bool, error) {
+	hash := sha256.Sum256([]byte(finding.Secret))
+	request := verifyRequest{
+		RuleID:         finding.RuleID,
+		File:           finding.File,
+		Target:         finding.Target,
+		Fingerprint:    finding.Fingerprint,
+		SecretHash:     hex.EncodeToString(hash[:]),
+		RedactedSecret: redactSecret(finding.Secret),
+	}
+	if s.opts.VerifySecrets {
+		request.Secret = finding.Secret
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode finding: %v", err)
+	}
+
+	client := &http.Client{Timeout: verifyTimeout}
+	resp, err := client.Post(s.opts.VerifyWebhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	var verdict verifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&verdict); err != nil {
+		return false, fmt.Errorf("failed to parse response: %v", err)
+	}
+	if !verdict.Accept {
+		s.debugf("Verification webhook rejected %s in %s: %s", finding.RuleID, finding.File, verdict.Reason)
+	}
+	return verdict.Accept, nil
+}
@@ -0,0 +1,123 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// viteMarkerRegex matches code Vite injects into the bundles it builds
+var viteMarkerRegex = regexp.MustCompile(`__vite__mapDeps|__vitePreload|vite:preloadError`)
+
+// viteManifestPaths are where Vite writes its build manifest, relative to
+// the app's base: .vite/ since Vite 5, the base itself before
+var viteManifestPaths = []string{".vite/manifest.json", "manifest.json"}
+
+// maxViteManifestSize bounds the manifest read
+const maxViteManifestSize = 10 << 20
+
+// viteManifestEntry is a chunk of a Vite build manifest
+type viteManifestEntry struct {
+	File string `json:"file"`
+}
+
+// viteBase returns the URL of the base of the Vite app fileURL belongs to,
+// the directory holding its assets/ directory (or the origin's root), if
+// content was built by Vite, and "" otherwise
+func viteBase(fileURL string, content string) string {
+	if !viteMarkerRegex.MatchString(content) {
+		return ""
+	}
+	u, err := url.Parse(fileURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	base := "/"
+	if i := strings.LastIndex(u.Path, "/assets/"); i >= 0 {
+		base = u.Path[:i+1]
+	}
+	return u.Scheme + "://" + u.Host + base
+}
+
+// addViteBase queues the manifest of the Vite app at base to be fetched,
+// once per scan
+func (s *Scanner) addViteBase(base string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.viteBases == nil {
+		s.viteBases = make(map[string]bool)
+	}
+	if !s.viteBases[base] {
+		s.viteBases[base] = true
+		s.pendingViteBases = append(s.pendingViteBases, base)
+	}
+}
+
+// fetchViteManifests fetches the manifests of the queued Vite apps and
+// queues the JavaScript chunks they list
+func (s *Scanner) fetchViteManifests(ctx context.Context) {
+	s.mu.Lock()
+	bases := s.pendingViteBases
+	s.pendingViteBases = nil
+	s.mu.Unlock()
+
+	for _, base := range bases {
+		for _, path := range viteManifestPaths {
+			if ctx.Err() != nil {
+				return
+			}
+			chunks, ok := s.fetchViteManifest(ctx, base, base+path)
+			if ok {
+				s.debugf("Found %d chunks in the Vite manifest %s%s", len(chunks), base, path)
+				s.addChunks(chunks)
+				break
+			}
+		}
+	}
+}
+
+// fetchViteManifest fetches the Vite manifest at manifestURL and returns the
+// URLs of the JavaScript files it lists, resolved against base. It reports
+// false if there's no manifest there, such as when manifest.json is a web
+// app manifest instead.
+func (s *Scanner) fetchViteManifest(ctx context.Context, base string, manifestURL string) ([]string, bool) {
+	resp, err := s.fetch(ctx, manifestURL, "Vite manifest")
+	if err != nil {
+		s.debugf("Failed to fetch %s: %v", manifestURL, err)
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxViteManifestSize))
+	if err != nil {
+		return nil, false
+	}
+	var manifest map[string]viteManifestEntry
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, false
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, false
+	}
+	var chunks []string
+	for _, entry := range manifest {
+		if entry.File == "" || !strings.HasSuffix(entry.File, ".js") && !strings.HasSuffix(entry.File, ".mjs") {
+			continue
+		}
+		if chunk, err := normalizeJSURL(baseURL, entry.File, false); err == nil {
+			chunks = append(chunks, chunk)
+		}
+	}
+	sort.Strings(chunks)
+	return chunks, len(chunks) > 0
+}
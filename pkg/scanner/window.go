@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// defaultWindowOverlap is the overlap between scan windows when
+// WindowOverlap isn't set, well above the length of typical secrets
+const defaultWindowOverlap = 4096
+
+// windowOverlap returns how many bytes each scan window shares with the
+// next. Validate keeps a set WindowOverlap within half a window, and the
+// default is cut down to fit.
+func (s *Scanner) windowOverlap() int {
+	if s.opts.WindowOverlap > 0 {
+		return s.opts.WindowOverlap
+	}
+	overlap := defaultWindowOverlap
+	if half := int(s.opts.ScanWindow / 2); overlap > half {
+		overlap = half
+	}
+	return overlap
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// checkStream scans the text body of file in windows of ScanWindow bytes,
+// each overlapping the last, so memory stays bounded whatever the file's
+// size. Only matches starting before a window's overlap are kept; the rest
+// are found whole in the next window, so a secret straddling two windows is
+// reported once if it's no longer than the overlap. It returns the number
+// of bytes read from body and adds the bytes matched, counting each overlap
+// once, and the time spent matching to report.
+func (s *Scanner) checkStream(url string, target string, contentType string, body io.Reader, report *FileReport) (int64, error) {
+	overlap := s.windowOverlap()
+	counter := &countingReader{r: body}
+	reader := utf8Reader(counter, contentType)
+
+	buf := make([]byte, s.opts.ScanWindow)
+	filled := 0
+	// start and lines are the byte offset and line count before the window
+	start := 0
+	lines := 0
+	seen := make(map[string]bool)
+	for {
+		n, err := io.ReadFull(reader, buf[filled:])
+		filled += n
+		final := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !final {
+			return counter.n, fmt.Errorf("failed to read JS file content: %v", err)
+		}
+
+		contentStr := string(buf[:filled])
+		cutoff := len(contentStr)
+		if !final {
+			cutoff -= overlap
+		}
+
+		matchStart := time.Now()
+		windowFindings := s.findSecrets(url, contentStr)
+		report.MatchDuration += time.Since(matchStart)
+
+		var findings []Finding
+		for _, finding := range windowFindings {
+			key := finding.RuleID + ":" + finding.Secret
+			if findingOffset(contentStr, finding) >= cutoff || seen[key] {
+				continue
+			}
+			seen[key] = true
+			findings = append(findings, finding)
+		}
+		setLineNumbers(contentStr, findings)
+		s.tagComments(contentStr, findings)
+		for i := range findings {
+			if findings[i].LineNumber > 0 {
+				findings[i].LineNumber += lines
+			}
+			if debug := findings[i].Debug; debug != nil {
+				debug.Offset += start
+				debug.SecretOffset += start
+			}
+		}
+		s.recordContent(url, target, contentStr, findings)
+
+		if final {
+			report.BytesScanned = int64(start + len(contentStr))
+			return counter.n, nil
+		}
+
+		// Carry the overlap into the next window
+		lines += strings.Count(contentStr[:cutoff], "\n")
+		start += cutoff
+		filled = copy(buf, buf[cutoff:filled])
+	}
+}
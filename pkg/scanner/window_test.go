@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nautical/jsweb/pkg/config"
+)
+
+// windowFinding is what must agree between windowed and whole-file scans
+type windowFinding struct {
+	Secret       string
+	LineNumber   int
+	Offset       int
+	SecretOffset int
+}
+
+// scanForWindowFindings checks content as a single file with opts and
+// returns its findings in file order
+func scanForWindowFindings(t *testing.T, cfg *config.Config, content string, opts Options) []windowFinding {
+	t.Helper()
+	const file = "https://example.com/app.js"
+	opts.Fetcher = stubFetcher{bodies: map[string]string{file: content}}
+	opts.DebugFindings = true
+	s := New(cfg, opts)
+	if err := s.checkFile(context.Background(), file, "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+
+	var findings []windowFinding
+	for _, finding := range s.GetFindings() {
+		findings = append(findings, windowFinding{finding.Secret, finding.LineNumber, finding.Debug.Offset, finding.Debug.SecretOffset})
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Offset < findings[j].Offset })
+	return findings
+}
+
+func TestCheckStreamSecretAcrossWindows(t *testing.T) {
+	cfg := &config.Config{Rules: []config.Rule{{
+		ID:          "test-token",
+		Regex:       `token\s*=\s*"([A-Za-z0-9]{16})"`,
+		SecretGroup: 1,
+		Entropy:     3,
+	}}}
+	secrets := []string{"Zq81Xk4Lp0WmR7tY", "Hb5Nc2Vd9Fg3Jk7L", "Qw4Er8Ty1Ui6Op2A"}
+
+	// Shift the secrets across the window ends and overlap cutoffs of
+	// 128-byte windows sharing 48 bytes
+	for shift := 0; shift < 80; shift += 3 {
+		var b strings.Builder
+		b.WriteString(strings.Repeat("x", shift) + "\n")
+		for i, secret := range secrets {
+			b.WriteString(strings.Repeat("var a = 1;\n", 7+i))
+			fmt.Fprintf(&b, "var token = \"%s\";\n", secret)
+		}
+		content := b.String()
+
+		whole := scanForWindowFindings(t, cfg, content, Options{})
+		windowed := scanForWindowFindings(t, cfg, content, Options{ScanWindow: 128, WindowOverlap: 48})
+		if len(whole) != len(secrets) {
+			t.Fatalf("shift %d: whole-file scan found %d secrets, want %d", shift, len(whole), len(secrets))
+		}
+		if len(windowed) != len(whole) {
+			t.Fatalf("shift %d: windowed scan found %+v, want each secret once as %+v", shift, windowed, whole)
+		}
+		for i := range whole {
+			if windowed[i] != whole[i] {
+				t.Errorf("shift %d: windowed finding %+v, want %+v", shift, windowed[i], whole[i])
+			}
+		}
+	}
+}
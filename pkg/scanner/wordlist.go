@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// probeWordlist requests each Wordlist path on the origin of pageURL and
+// returns the URLs that respond with a scannable file, in wordlist order.
+// Files already seen and paths disallowed by robots.txt are skipped, and
+// probes share the rate limit and per-host concurrency of file fetches.
+func (s *Scanner) probeWordlist(ctx context.Context, pageURL string, concurrency int) []string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	origin := u.Scheme + "://" + u.Host
+	robots := s.fetchRobots(ctx, origin)
+
+	var candidates []string
+	s.mu.Lock()
+	for _, path := range s.opts.Wordlist {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		if !robots.allowed(path) {
+			s.debugf("Skipping %s%s disallowed by robots.txt", origin, path)
+			continue
+		}
+		if candidate := origin + path; !s.seenFiles[s.dedupeKey(candidate)] {
+			candidates = append(candidates, candidate)
+		}
+	}
+	s.mu.Unlock()
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	found := make([]bool, len(candidates))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				found[i] = s.probeFile(ctx, candidates[i])
+			}
+		}()
+	}
+	for i := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var files []string
+	for i, candidate := range candidates {
+		if found[i] {
+			files = append(files, candidate)
+		}
+	}
+	s.debugf("Found %d of %d wordlist paths on %s", len(files), len(candidates), origin)
+	return files
+}
+
+// probeFile checks if fileURL responds with a scannable file, reading no
+// more of its body than needed to sniff a generic content type
+func (s *Scanner) probeFile(ctx context.Context, fileURL string) bool {
+	if err := s.limiter.Wait(ctx); err != nil {
+		return false
+	}
+	release := s.acquireHost(fileURL)
+	defer release()
+
+	req, err := s.newRequest(ctx, fileURL)
+	if err != nil {
+		return false
+	}
+	resp, err := s.fetcher.Do(req)
+	if err != nil {
+		s.debugf("Failed to probe %s: %v", fileURL, err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if s.isScannableContentType(contentType) {
+		return true
+	}
+	var head []byte
+	if isGenericContentType(contentType) {
+		head = peekBody(resp)
+	}
+	return s.sniffScannable(contentType, fileURL, head) ||
+		(s.opts.AllowAnyContentType && s.isScannableFile(fileURL))
+}
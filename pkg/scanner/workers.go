@@ -0,0 +1,88 @@
+package scanner
+
+import (
+	"net/url"
+	"regexp"
+)
+
+var (
+	// workerRegex matches new Worker(...), new SharedWorker(...), and
+	// serviceWorker.register(...) calls with a literal script URL, which may
+	// be wrapped in new URL(..., import.meta.url)
+	workerRegex = regexp.MustCompile("(?:new\\s+(?:Shared)?Worker|serviceWorker\\s*\\.\\s*register)\\s*\\(\\s*(new\\s+URL\\s*\\(\\s*)?[\"'`]([^\"'`\\s]+)[\"'`]")
+	// importScriptsRegex matches the argument list of importScripts(...)
+	importScriptsRegex = regexp.MustCompile(`importScripts\s*\(([^)]*)\)`)
+	// stringLiteralRegex matches a quoted string literal
+	stringLiteralRegex = regexp.MustCompile("[\"'`]([^\"'`\\s]+)[\"'`]")
+)
+
+// extractWorkerURLs returns the URLs of worker scripts started by the file
+// at fileURL and of scripts it loads with importScripts. importScripts and
+// new URL(..., import.meta.url) resolve against the file itself; worker
+// constructors resolve against the page, approximated by target when known.
+func (s *Scanner) extractWorkerURLs(fileURL string, target string, content string) []string {
+	fileBase, err := url.Parse(fileURL)
+	if err != nil {
+		return nil
+	}
+	pageBase := fileBase
+	if target != "" {
+		if u, err := url.Parse(target); err == nil {
+			pageBase = u
+		}
+	}
+
+	var workers []string
+	seen := make(map[string]bool)
+	add := func(base *url.URL, ref string) {
+		normalized, err := normalizeJSURL(base, ref, s.opts.StripQuery)
+		if err != nil || normalized == fileURL || !s.isScannableFile(normalized) || seen[normalized] {
+			return
+		}
+		seen[normalized] = true
+		workers = append(workers, normalized)
+	}
+
+	for _, match := range workerRegex.FindAllStringSubmatch(content, -1) {
+		if match[1] != "" {
+			add(fileBase, match[2])
+		} else {
+			add(pageBase, match[2])
+		}
+	}
+	for _, match := range importScriptsRegex.FindAllStringSubmatch(content, -1) {
+		for _, literal := range stringLiteralRegex.FindAllStringSubmatch(match[1], -1) {
+			add(fileBase, literal[1])
+		}
+	}
+
+	return workers
+}
+
+// addWorkers queues worker script URLs, and config files fetched at
+// runtime, that haven't been checked or queued yet. Unlike chunks they're followed to any depth, since each URL is only
+// checked once.
+func (s *Scanner) addWorkers(workers []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seenFiles == nil {
+		s.seenFiles = make(map[string]bool)
+	}
+	for _, worker := range workers {
+		if key := s.dedupeKey(worker); !s.seenFiles[key] {
+			s.seenFiles[key] = true
+			s.pendingWorkers = append(s.pendingWorkers, worker)
+		}
+	}
+}
+
+// takeWorkers returns and clears the queued worker script URLs
+func (s *Scanner) takeWorkers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workers := s.pendingWorkers
+	s.pendingWorkers = nil
+	return workers
+}
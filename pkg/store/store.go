@@ -0,0 +1,124 @@
+// Package store persists scan runs and their findings in a SQLite database
+// so leaks can be tracked over time with SQL.
+package store
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nautical/jsweb/pkg/scanner"
+
+	// Register the pure-Go SQLite driver, which avoids cgo
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the database/sql driver registered by modernc.org/sqlite
+const driverName = "sqlite"
+
+// schema creates the tables on first use
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	run_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp TEXT NOT NULL,
+	version TEXT NOT NULL,
+	targets TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS findings (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	run_id INTEGER NOT NULL REFERENCES runs(run_id),
+	timestamp TEXT NOT NULL,
+	target TEXT NOT NULL,
+	rule_id TEXT NOT NULL,
+	file TEXT NOT NULL,
+	secret_hash TEXT NOT NULL,
+	secret TEXT,
+	entropy REAL NOT NULL,
+	line_number INTEGER NOT NULL,
+	severity TEXT NOT NULL,
+	fingerprint TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS findings_fingerprint ON findings(fingerprint);
+CREATE INDEX IF NOT EXISTS findings_run ON findings(run_id);
+`
+
+// Store is a SQLite database of scan runs
+type Store struct {
+	db           *sql.DB
+	storeSecrets bool
+}
+
+// Open opens or creates the database at path. Secrets are stored only as a
+// SHA-256 hash unless storeSecrets is set.
+func Open(path string, storeSecrets bool) (*Store, error) {
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create database schema: %v", err)
+	}
+
+	return &Store{db: db, storeSecrets: storeSecrets}, nil
+}
+
+// Close closes the database
+func (st *Store) Close() error {
+	return st.db.Close()
+}
+
+// SaveRun records a run started at timestamp and its findings in a single
+// transaction, returning the new run ID
+func (st *Store) SaveRun(timestamp time.Time, version string, targets []string, findings []scanner.Finding) (int64, error) {
+	tx, err := st.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	ts := timestamp.UTC().Format(time.RFC3339)
+	result, err := tx.Exec(`INSERT INTO runs (timestamp, version, targets) VALUES (?, ?, ?)`,
+		ts, version, strings.Join(targets, "\n"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run: %v", err)
+	}
+	runID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run: %v", err)
+	}
+
+	insert, err := tx.Prepare(`INSERT INTO findings
+		(run_id, timestamp, target, rule_id, file, secret_hash, secret, entropy, line_number, severity, fingerprint)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare finding insert: %v", err)
+	}
+	defer insert.Close()
+
+	for _, finding := range findings {
+		var secret interface{}
+		if st.storeSecrets {
+			secret = finding.Secret
+		}
+		_, err := insert.Exec(runID, ts, finding.Target, finding.RuleID, finding.File, hashSecret(finding.Secret),
+			secret, finding.Entropy, finding.LineNumber, finding.Severity, finding.Fingerprint)
+		if err != nil {
+			return 0, fmt.Errorf("failed to record finding: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit run: %v", err)
+	}
+	return runID, nil
+}
+
+// hashSecret returns the hex SHA-256 hash of a secret
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,450 @@
+// Package tui is an interactive terminal browser for triaging findings
+// after a scan
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nautical/jsweb/pkg/scanner"
+	"golang.org/x/term"
+)
+
+// Terminal control sequences
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	cursorHome  = "\x1b[H"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+	reverse     = "\x1b[7m"
+	bold        = "\x1b[1m"
+	reset       = "\x1b[0m"
+)
+
+// Keys, as returned by parseKeys
+const (
+	keyUp       = "up"
+	keyDown     = "down"
+	keyPageUp   = "pgup"
+	keyPageDown = "pgdown"
+	keyHome     = "home"
+	keyEnd      = "end"
+	keyEnter    = "enter"
+	keyCtrlC    = "ctrl-c"
+)
+
+// Default terminal size, used when it can't be read
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// maxRuleWidth bounds the rule ID column of the list
+const maxRuleWidth = 30
+
+// helpLine lists the keys at the bottom of the screen
+const helpLine = "up/down move  enter details  a accept  r rule  s severity  c clear filters  q save and quit  ctrl-c quit"
+
+// Run shows findings in a full-screen browser on the terminal in and out
+// until the user quits, and returns the fingerprints of the findings they
+// accepted, or nil if they quit with Ctrl-C. The list is redrawn after each
+// key, which also picks up terminal resizes.
+func Run(in *os.File, out *os.File, findings []scanner.Finding) ([]string, error) {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the terminal: %v", err)
+	}
+	defer term.Restore(int(in.Fd()), state)
+
+	io.WriteString(out, enterScreen)
+	defer io.WriteString(out, leaveScreen)
+
+	m := newModel(findings)
+	buf := make([]byte, 64)
+	for {
+		m.width, m.height = defaultWidth, defaultHeight
+		if width, height, err := term.GetSize(int(out.Fd())); err == nil && width > 0 && height > 0 {
+			m.width, m.height = width, height
+		}
+		if _, err := io.WriteString(out, m.render()); err != nil {
+			return nil, fmt.Errorf("failed to draw: %v", err)
+		}
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %v", err)
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			switch key {
+			case "q":
+				return m.acceptedFingerprints(), nil
+			case keyCtrlC:
+				return nil, nil
+			}
+			m.update(key)
+		}
+	}
+}
+
+// parseKeys splits terminal input into keys: the names above for special
+// keys, and the character itself otherwise
+func parseKeys(input []byte) []string {
+	sequences := []struct {
+		seq string
+		key string
+	}{
+		{"\x1b[A", keyUp}, {"\x1bOA", keyUp},
+		{"\x1b[B", keyDown}, {"\x1bOB", keyDown},
+		{"\x1b[5~", keyPageUp}, {"\x1b[6~", keyPageDown},
+		{"\x1b[H", keyHome}, {"\x1bOH", keyHome}, {"\x1b[1~", keyHome},
+		{"\x1b[F", keyEnd}, {"\x1bOF", keyEnd}, {"\x1b[4~", keyEnd},
+	}
+
+	var keys []string
+	s := string(input)
+	for len(s) > 0 {
+		matched := false
+		for _, sequence := range sequences {
+			if strings.HasPrefix(s, sequence.seq) {
+				keys = append(keys, sequence.key)
+				s = s[len(sequence.seq):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		switch c := s[0]; {
+		case c == '\x1b':
+			// Skip the rest of an unknown escape sequence
+			s = s[1:]
+			if len(s) > 0 && (s[0] == '[' || s[0] == 'O') {
+				i := 1
+				for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+					i++
+				}
+				if i < len(s) {
+					i++
+				}
+				s = s[i:]
+			}
+		case c == '\r' || c == '\n':
+			keys = append(keys, keyEnter)
+			s = s[1:]
+		case c == 0x03:
+			keys = append(keys, keyCtrlC)
+			s = s[1:]
+		default:
+			keys = append(keys, s[:1])
+			s = s[1:]
+		}
+	}
+	return keys
+}
+
+// model is the state of the finding browser
+type model struct {
+	findings []scanner.Finding
+	// visible are the indexes of the findings that pass the filters
+	visible []int
+	// cursor is the selected position in visible, and offset the first
+	// position shown
+	cursor int
+	offset int
+	// expanded shows the selected finding's details and snippet
+	expanded bool
+	// accepted holds the fingerprints of the accepted findings
+	accepted map[string]bool
+	// rules are the rule IDs of the findings, for cycling the rule filter
+	rules []string
+	// rule and severity filter the list, "" showing all
+	rule     string
+	severity string
+
+	width  int
+	height int
+}
+
+// newModel returns a model listing every finding
+func newModel(findings []scanner.Finding) *model {
+	m := &model{findings: findings, accepted: make(map[string]bool)}
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		if !seen[finding.RuleID] {
+			seen[finding.RuleID] = true
+			m.rules = append(m.rules, finding.RuleID)
+		}
+	}
+	sort.Strings(m.rules)
+	m.filter()
+	return m
+}
+
+// filter recomputes the visible findings, keeping the selection on the
+// same finding when it's still shown
+func (m *model) filter() {
+	selected := -1
+	if m.cursor < len(m.visible) {
+		selected = m.visible[m.cursor]
+	}
+
+	m.visible = m.visible[:0]
+	m.cursor = 0
+	for i, finding := range m.findings {
+		if m.rule != "" && finding.RuleID != m.rule {
+			continue
+		}
+		if m.severity != "" && !scanner.AtLeastSeverity(finding.Severity, m.severity) {
+			continue
+		}
+		if i == selected {
+			m.cursor = len(m.visible)
+		}
+		m.visible = append(m.visible, i)
+	}
+}
+
+// update applies a key other than quitting
+func (m *model) update(key string) {
+	page := clamp(m.listHeight()-1, 1, m.listHeight())
+	switch key {
+	case keyUp, "k":
+		m.cursor--
+	case keyDown, "j":
+		m.cursor++
+	case keyPageUp:
+		m.cursor -= page
+	case keyPageDown:
+		m.cursor += page
+	case keyHome, "g":
+		m.cursor = 0
+	case keyEnd, "G":
+		m.cursor = len(m.visible) - 1
+	case keyEnter, " ":
+		m.expanded = !m.expanded
+	case "a":
+		if finding, ok := m.selected(); ok {
+			m.accepted[finding.Fingerprint] = !m.accepted[finding.Fingerprint]
+		}
+	case "r":
+		m.rule = next(append([]string{""}, m.rules...), m.rule)
+		m.filter()
+	case "s":
+		m.severity = next(append([]string{""}, scanner.Severities...), m.severity)
+		m.filter()
+	case "c":
+		m.rule, m.severity = "", ""
+		m.filter()
+	}
+	m.cursor = clamp(m.cursor, 0, len(m.visible)-1)
+}
+
+// next returns the value after current in values, wrapping around
+func next(values []string, current string) string {
+	for i, value := range values {
+		if value == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
+
+// selected returns the finding under the cursor
+func (m *model) selected() (scanner.Finding, bool) {
+	if m.cursor >= len(m.visible) {
+		return scanner.Finding{}, false
+	}
+	return m.findings[m.visible[m.cursor]], true
+}
+
+// acceptedFingerprints returns the fingerprints of the accepted findings in
+// list order
+func (m *model) acceptedFingerprints() []string {
+	var fingerprints []string
+	seen := make(map[string]bool)
+	for _, finding := range m.findings {
+		if m.accepted[finding.Fingerprint] && !seen[finding.Fingerprint] {
+			seen[finding.Fingerprint] = true
+			fingerprints = append(fingerprints, finding.Fingerprint)
+		}
+	}
+	return fingerprints
+}
+
+// detailHeight returns the number of lines of the details pane
+func (m *model) detailHeight() int {
+	if !m.expanded {
+		return 0
+	}
+	return m.height / 2
+}
+
+// listHeight returns the number of list rows that fit on screen, below the
+// header and above the details pane and help line
+func (m *model) listHeight() int {
+	return clamp(m.height-2-m.detailHeight(), 1, m.height)
+}
+
+// render draws the whole screen
+func (m *model) render() string {
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	line := func(text string, style string) {
+		text = fit(text, m.width)
+		if style != "" {
+			text = style + text + reset
+		}
+		b.WriteString(text + clearLine + "\r\n")
+	}
+
+	rule, severity := m.rule, m.severity+"+"
+	if rule == "" {
+		rule = "all"
+	}
+	if m.severity == "" {
+		severity = "all"
+	}
+	line(fmt.Sprintf("jsweb: %d findings, %d shown, %d accepted | rule: %s | severity: %s",
+		len(m.findings), len(m.visible), len(m.acceptedFingerprints()), rule, severity), bold)
+
+	// Keep the cursor on screen
+	rows := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = clamp(m.offset, 0, len(m.visible)-rows)
+
+	ruleWidth := 0
+	for _, i := range m.visible {
+		if width := len(m.findings[i].RuleID); width > ruleWidth {
+			ruleWidth = width
+		}
+	}
+	ruleWidth = clamp(ruleWidth, 0, maxRuleWidth)
+	for row := 0; row < rows; row++ {
+		pos := m.offset + row
+		if pos >= len(m.visible) {
+			if row == 0 {
+				line("  No findings match the filters", "")
+			} else {
+				line("", "")
+			}
+			continue
+		}
+		style := ""
+		if pos == m.cursor {
+			style = reverse
+		}
+		line(m.row(m.findings[m.visible[pos]], ruleWidth), style)
+	}
+
+	if m.expanded {
+		details := m.details()
+		for i := 0; i < m.detailHeight(); i++ {
+			if i < len(details) {
+				line(details[i], "")
+			} else {
+				line("", "")
+			}
+		}
+	}
+
+	b.WriteString(fit(helpLine, m.width) + clearLine + clearBelow)
+	return b.String()
+}
+
+// row formats a finding as a list row
+func (m *model) row(finding scanner.Finding, ruleWidth int) string {
+	mark := "[ ]"
+	if m.accepted[finding.Fingerprint] {
+		mark = "[x]"
+	}
+	severity := finding.Severity
+	if severity == "" {
+		severity = "-"
+	}
+	ruleID := finding.RuleID
+	if len(ruleID) > ruleWidth {
+		ruleID = ruleID[:ruleWidth]
+	}
+	return fmt.Sprintf("%s %-8s %-*s  %s  %s", mark, strings.ToUpper(severity), ruleWidth, ruleID, location(finding), sanitize(finding.Secret))
+}
+
+// details returns the lines of the selected finding's details pane
+func (m *model) details() []string {
+	finding, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	lines := []string{
+		strings.Repeat("-", m.width),
+		"Rule:        " + finding.RuleID + " (" + sanitize(finding.Description) + ")",
+		"File:        " + location(finding),
+	}
+	if finding.Target != "" && finding.Target != finding.File {
+		lines = append(lines, "Target:      "+sanitize(finding.Target))
+	}
+	lines = append(lines, "Secret:      "+sanitize(finding.Secret))
+	if finding.ContextIdentifier != "" {
+		lines = append(lines, "Assigned to: "+sanitize(finding.ContextIdentifier))
+	}
+	if len(finding.Tags) > 0 {
+		lines = append(lines, "Tags:        "+sanitize(strings.Join(finding.Tags, ", ")))
+	}
+	lines = append(lines, "Fingerprint: "+finding.Fingerprint, "")
+	for _, snippetLine := range strings.Split(finding.CodeSnippet, "\n") {
+		lines = append(lines, "  "+sanitize(snippetLine))
+	}
+	return lines
+}
+
+// location returns a finding's file and line number
+func location(finding scanner.Finding) string {
+	if finding.LineNumber > 0 {
+		return fmt.Sprintf("%s:%d", sanitize(finding.File), finding.LineNumber)
+	}
+	return sanitize(finding.File)
+}
+
+// sanitize replaces control characters, which scanned content could use to
+// drive the terminal, and turns tabs into spaces
+func sanitize(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			return '?'
+		}
+		return r
+	}, text)
+}
+
+// fit truncates text to width characters
+func fit(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width])
+}
+
+// clamp limits value to the range from low to high, preferring low when
+// the range is empty
+func clamp(value int, low int, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
@@ -0,0 +1,210 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// gcsScope is the OAuth scope needed to write objects
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// googleTokenURL is the OAuth token endpoint of user credentials
+const googleTokenURL = "https://oauth2.googleapis.com/token"
+
+// gcsMetadataTokenURL returns the token of the default service account on
+// Google Cloud compute (GCE, GKE, Cloud Run)
+const gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// googleCredentials is an application default credentials file, either a
+// service account key or gcloud's user credentials
+type googleCredentials struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// tokenResponse is an OAuth token endpoint's response
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+// resolveGCSToken returns an access token from GOOGLE_OAUTH_ACCESS_TOKEN,
+// else from the application default credentials file
+// (GOOGLE_APPLICATION_CREDENTIALS, or the one 'gcloud auth
+// application-default login' writes), else from the metadata server when
+// running on Google Cloud
+func resolveGCSToken(ctx context.Context, client *http.Client) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		path = homePath(".config", "gcloud", "application_default_credentials.json")
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		var creds googleCredentials
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return "", fmt.Errorf("failed to parse Google credentials %s: %v", path, err)
+		}
+		switch creds.Type {
+		case "service_account":
+			return serviceAccountToken(ctx, client, creds)
+		case "authorized_user":
+			return requestToken(ctx, client, googleTokenURL, url.Values{
+				"grant_type":    {"refresh_token"},
+				"client_id":     {creds.ClientID},
+				"client_secret": {creds.ClientSecret},
+				"refresh_token": {creds.RefreshToken},
+			})
+		default:
+			return "", fmt.Errorf("unsupported Google credentials type %q in %s", creds.Type, path)
+		}
+	}
+
+	// The metadata server is link-local, so it's never reached through the
+	// proxy
+	token, err := metadataToken(ctx, &http.Client{Timeout: 5 * time.Second})
+	if err != nil {
+		return "", fmt.Errorf("%w for GCS: set GOOGLE_APPLICATION_CREDENTIALS or GOOGLE_OAUTH_ACCESS_TOKEN, or run on Google Cloud", ErrNoCredentials)
+	}
+	return token, nil
+}
+
+// serviceAccountToken exchanges a JWT signed with the service account's key
+// for an access token
+func serviceAccountToken(ctx context.Context, client *http.Client, creds googleCredentials) (string, error) {
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("failed to parse the private key of %s", creds.ClientEmail)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the private key of %s: %v", creds.ClientEmail, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("the private key of %s isn't an RSA key", creds.ClientEmail)
+	}
+
+	tokenURI := creds.TokenURI
+	if tokenURI == "" {
+		tokenURI = googleTokenURL
+	}
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   creds.ClientEmail,
+		"scope": gcsScope,
+		"aud":   tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %v", err)
+	}
+
+	return requestToken(ctx, client, tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	})
+}
+
+// requestToken posts form to an OAuth token endpoint and returns the access
+// token granted
+func requestToken(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doTokenRequest(client, req)
+}
+
+// metadataToken requests the default service account's token from the
+// metadata server
+func metadataToken(ctx context.Context, client *http.Client) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcsMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	return doTokenRequest(client, req)
+}
+
+// doTokenRequest sends a token request and decodes its access token
+func doTokenRequest(client *http.Client, req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Google access token: %v", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return "", fmt.Errorf("failed to get Google access token: %v", err)
+	}
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("failed to get Google access token: invalid response")
+	}
+	return token.AccessToken, nil
+}
+
+// uploadGCS stores body as key in bucket with a JSON API media upload.
+// STORAGE_EMULATOR_HOST points uploads at an emulator, which needs no
+// credentials.
+func uploadGCS(ctx context.Context, client *http.Client, bucket string, key string, body []byte, contentType string) error {
+	endpoint := "https://storage.googleapis.com"
+	token := ""
+	if emulator := os.Getenv("STORAGE_EMULATOR_HOST"); emulator != "" {
+		endpoint = strings.TrimRight(emulator, "/")
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	} else {
+		var err error
+		if token, err = resolveGCSToken(ctx, client); err != nil {
+			return err
+		}
+	}
+
+	uploadURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", endpoint, url.PathEscape(bucket), url.QueryEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create GCS request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to GCS: %v", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to upload to GCS: %v", err)
+	}
+	return nil
+}
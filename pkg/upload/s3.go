@@ -0,0 +1,216 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultS3Region is used when no region is configured
+const defaultS3Region = "us-east-1"
+
+// awsCredentials are an AWS access key, with a session token for
+// temporary credentials
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// awsProfile returns the AWS profile in use
+func awsProfile() string {
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// resolveAWSCredentials reads credentials from the AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY environment variables, or else from the profile's
+// section of the shared credentials file
+func resolveAWSCredentials() (awsCredentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return awsCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		path = homePath(".aws", "credentials")
+	}
+	values := readINI(path, awsProfile())
+	if values["aws_access_key_id"] == "" || values["aws_secret_access_key"] == "" {
+		return awsCredentials{}, fmt.Errorf("%w for S3: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or configure profile %s in %s", ErrNoCredentials, awsProfile(), path)
+	}
+	return awsCredentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	}, nil
+}
+
+// resolveAWSRegion returns the region from AWS_REGION, AWS_DEFAULT_REGION,
+// or the profile's section of the config file, defaulting to us-east-1
+func resolveAWSRegion() string {
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(name); region != "" {
+			return region
+		}
+	}
+
+	path := os.Getenv("AWS_CONFIG_FILE")
+	if path == "" {
+		path = homePath(".aws", "config")
+	}
+	section := "profile " + awsProfile()
+	if awsProfile() == "default" {
+		section = "default"
+	}
+	if region := readINI(path, section)["region"]; region != "" {
+		return region
+	}
+	return defaultS3Region
+}
+
+// s3ObjectURL returns the URL of key in bucket. Buckets are addressed
+// virtual-hosted style, except behind a custom endpoint (AWS_ENDPOINT_URL_S3
+// or AWS_ENDPOINT_URL, such as MinIO) and for bucket names with dots, which
+// don't match the TLS certificate.
+func s3ObjectURL(bucket string, key string, region string) (*url.URL, error) {
+	path := "/" + escapeS3Path(key)
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_S3")
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	switch {
+	case endpoint != "":
+		u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 endpoint %s: %v", endpoint, err)
+		}
+		base := u.EscapedPath()
+		u.Path += "/" + bucket + "/" + key
+		u.RawPath = base + "/" + bucket + path
+		return u, nil
+	case strings.Contains(bucket, "."):
+		return &url.URL{Scheme: "https", Host: "s3." + region + ".amazonaws.com", Path: "/" + bucket + "/" + key, RawPath: "/" + bucket + path}, nil
+	default:
+		return &url.URL{Scheme: "https", Host: bucket + ".s3." + region + ".amazonaws.com", Path: "/" + key, RawPath: path}, nil
+	}
+}
+
+// escapeS3Path percent-encodes key as S3 expects in signed paths: every
+// byte but unreserved characters and slashes
+func escapeS3Path(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// uploadS3 stores body as key in bucket with a PUT signed with AWS
+// Signature Version 4
+func uploadS3(ctx context.Context, client *http.Client, bucket string, key string, body []byte, contentType string) error {
+	creds, err := resolveAWSCredentials()
+	if err != nil {
+		return err
+	}
+	region := resolveAWSRegion()
+	objectURL, err := s3ObjectURL(bucket, key, region)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	signS3(req, body, creds, region, time.Now().UTC())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to S3: %v", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to upload to S3: %v", err)
+	}
+	return nil
+}
+
+// signS3 adds the AWS Signature Version 4 Authorization header for S3 to
+// req, signing its host, content type, payload hash, date, and any
+// session token
+func signS3(req *http.Request, body []byte, creds awsCredentials, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,129 @@
+// Package upload copies reports to object storage (Amazon S3 and Google
+// Cloud Storage), finding credentials the way the providers' own tools do.
+package upload
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoCredentials means no credentials for the storage provider were
+// found in the environment
+var ErrNoCredentials = errors.New("no credentials found")
+
+// Destination is a bucket and key prefix reports are uploaded under
+type Destination struct {
+	// Scheme is "s3" or "gs"
+	Scheme string
+	Bucket string
+	// Prefix is prepended to object names, without a trailing slash
+	Prefix string
+}
+
+// Parse parses an s3://bucket/prefix or gs://bucket/prefix URL
+func Parse(rawURL string) (Destination, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Destination{}, fmt.Errorf("invalid upload URL %s: %v", rawURL, err)
+	}
+	if u.Scheme != "s3" && u.Scheme != "gs" {
+		return Destination{}, fmt.Errorf("invalid upload URL %s: must start with s3:// or gs://", rawURL)
+	}
+	if u.Host == "" {
+		return Destination{}, fmt.Errorf("invalid upload URL %s: missing bucket", rawURL)
+	}
+	return Destination{Scheme: u.Scheme, Bucket: u.Host, Prefix: strings.Trim(u.Path, "/")}, nil
+}
+
+// Key returns the object key of name under the destination's prefix
+func (d Destination) Key(name string) string {
+	if d.Prefix == "" {
+		return name
+	}
+	return d.Prefix + "/" + name
+}
+
+// String returns the destination as a URL
+func (d Destination) String() string {
+	return d.Scheme + "://" + d.Bucket + "/" + d.Prefix
+}
+
+// Upload stores body as name under d using client, and returns the URL of
+// the object
+func Upload(ctx context.Context, client *http.Client, d Destination, name string, body []byte, contentType string) (string, error) {
+	var err error
+	switch d.Scheme {
+	case "s3":
+		err = uploadS3(ctx, client, d.Bucket, d.Key(name), body, contentType)
+	case "gs":
+		err = uploadGCS(ctx, client, d.Bucket, d.Key(name), body, contentType)
+	default:
+		err = fmt.Errorf("unsupported storage scheme %s", d.Scheme)
+	}
+	if err != nil {
+		return "", err
+	}
+	return d.Scheme + "://" + d.Bucket + "/" + d.Key(name), nil
+}
+
+// checkResponse returns an error with the start of the body for
+// unsuccessful responses
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// readINI returns the keys of section in the INI file at path, or nil if
+// the file or section doesn't exist
+func readINI(path string, section string) map[string]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var values map[string]string
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			if values == nil {
+				values = make(map[string]string)
+			}
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// homePath returns path under the user's home directory, or "" if it's
+// unknown
+func homePath(path ...string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(append([]string{home}, path...)...)
+}
@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"bufio"
+	"net/url"
+	"os"
+	"regexp"
 	"strings"
 )
 
@@ -14,9 +18,33 @@ func Contains(slice []string, item string) bool {
 	return false
 }
 
-// IsJavaScriptFile checks if a URL points to a JavaScript file
-func IsJavaScriptFile(url string) bool {
-	return strings.HasSuffix(url, ".js")
+// IsJavaScriptFile checks if a URL points to a JavaScript file, ignoring
+// any query string or fragment
+func IsJavaScriptFile(rawURL string) bool {
+	return hasPathSuffix(rawURL, ".js")
+}
+
+// IsJSONFile checks if a URL points to a JSON file
+func IsJSONFile(rawURL string) bool {
+	return hasPathSuffix(rawURL, ".json")
+}
+
+// IsYAMLFile checks if a URL points to a YAML file
+func IsYAMLFile(rawURL string) bool {
+	return hasPathSuffix(rawURL, ".yaml") || hasPathSuffix(rawURL, ".yml")
+}
+
+// IsWasmFile checks if a URL points to a WebAssembly module
+func IsWasmFile(rawURL string) bool {
+	return hasPathSuffix(rawURL, ".wasm")
+}
+
+// hasPathSuffix checks if the path of a URL ends with suffix
+func hasPathSuffix(rawURL string, suffix string) bool {
+	if u, err := url.Parse(rawURL); err == nil {
+		return strings.HasSuffix(u.Path, suffix)
+	}
+	return strings.HasSuffix(rawURL, suffix)
 }
 
 // IsThirdPartyDomain checks if a URL belongs to a third-party service
@@ -57,3 +85,63 @@ func IsThirdPartyDomain(url string) bool {
 	}
 	return false
 }
+
+// CompileFilePattern compiles a file pattern into a regex. Patterns prefixed
+// with "re:" are regular expressions; anything else is a glob where "*"
+// matches any run of characters (including "/") and "?" matches one.
+func CompileFilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "re:") {
+		return regexp.Compile(strings.TrimPrefix(pattern, "re:"))
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// MatchesFilePattern checks if a compiled file pattern matches the URL, its
+// path, or its base name
+func MatchesFilePattern(re *regexp.Regexp, rawURL string) bool {
+	if re.MatchString(rawURL) {
+		return true
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return false
+	}
+	base := u.Path[strings.LastIndex(u.Path, "/")+1:]
+	return re.MatchString(u.Path) || re.MatchString(base)
+}
+
+// ReadPatternFile reads one pattern per line from path, skipping blank lines
+// and lines starting with "#"
+func ReadPatternFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	lineScanner := bufio.NewScanner(file)
+	for lineScanner.Scan() {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, lineScanner.Err()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+)
+
+// hiddenFlags are flags for profiling jsweb itself, left out of the usage
+// text
+var hiddenFlags = map[string]bool{"cpuprofile": true, "memprofile": true}
+
+// stopProfiles finishes the profiles started by startProfiles
+var stopProfiles = func() {}
+
+// startProfiles starts a CPU profile written to cpuPath and arranges for a
+// heap profile to be written to memPath when stopProfiles is called. Either
+// path may be empty.
+func startProfiles(cpuPath, memPath string) error {
+	var cpuFile *os.File
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %v", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to start CPU profile: %v", err)
+		}
+		cpuFile = f
+	}
+
+	var once sync.Once
+	stopProfiles = func() {
+		once.Do(func() {
+			if cpuFile != nil {
+				pprof.StopCPUProfile()
+				cpuFile.Close()
+			}
+			if memPath != "" {
+				if err := writeHeapProfile(memPath); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+		})
+	}
+	return nil
+}
+
+// writeHeapProfile writes a heap profile of live objects to path
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %v", err)
+	}
+	defer f.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write memory profile: %v", err)
+	}
+	return nil
+}
+
+// exit flushes any profiles, which os.Exit would skip, and exits with code
+func exit(code int) {
+	stopProfiles()
+	os.Exit(code)
+}